@@ -0,0 +1,127 @@
+// Copyright © 2019 Ken'ichiro Oyama <k1lowxb@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/datasource"
+	"github.com/labstack/gommon/color"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "manage config file",
+	Long:  `'tbls config' manages config file ( .tbls.yml ).`,
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [DSN]",
+	Short: "validate config file",
+	Long: `'tbls config validate' validates config file strictly ( unknown keys, invalid values ),
+and validates that tables and columns referenced in config exist in the database if DSN is set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.NewConfig()
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		options, err := loadConfigValidateArgs(args)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		err = c.Load(configPath, options...)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		if strings.Join(c.DSN, "") == "" {
+			return
+		}
+
+		s, err := datasource.Analyze(c.DSN)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		errs := c.ValidateSchema(s)
+		if len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Println(err)
+			}
+			fmt.Println(color.White(fmt.Sprintf("\n%d detected", len(errs)), color.B))
+			os.Exit(1)
+		}
+	},
+}
+
+// configSchemaCmd represents the config schema command
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "output JSON Schema of config file",
+	Long:  `'tbls config schema' outputs JSON Schema of config file for editor completion and validation.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		b, err := config.JSONSchema()
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+		_, err = os.Stdout.Write(b)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func loadConfigValidateArgs(args []string) ([]config.Option, error) {
+	options := []config.Option{config.Strict(true)}
+	if len(args) > 1 {
+		return options, errors.WithStack(errors.New("too many arguments"))
+	}
+	if profile != "" {
+		options = append(options, config.Profile(profile))
+	}
+	if len(args) == 1 {
+		options = append(options, config.DSN(strings.Split(args[0], ";")))
+	}
+	return options, nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configValidateCmd.Flags().StringVarP(&configPath, "config", "c", "", "config file path")
+	configValidateCmd.Flags().StringVarP(&profile, "profile", "", "", "profile in config file ( default: $TBLS_PROFILE )")
+}
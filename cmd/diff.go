@@ -21,6 +21,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -32,6 +33,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// exit status of `tbls diff`
+const (
+	exitDiffDocStale     = 1
+	exitDiffError        = 2
+	exitDiffCommentStale = 3
+	exitDiffERStale      = 4
+)
+
+// diffFormat is a option that diff output format
+var diffFormat string
+
+// failOn is the kinds of difference that fail `tbls diff`
+var failOn []string
+
 // diffCmd represents the diff command
 var diffCmd = &cobra.Command{
 	Use:   "diff [DSN] [DOC_PATH]",
@@ -41,7 +56,7 @@ var diffCmd = &cobra.Command{
 		c, err := config.NewConfig()
 		if err != nil {
 			printError(err)
-			os.Exit(1)
+			os.Exit(exitDiffError)
 		}
 
 		if configPath == "" && additionalDataPath != "" {
@@ -49,47 +64,117 @@ var diffCmd = &cobra.Command{
 			configPath = additionalDataPath
 		}
 
+		err = validateFailOn(failOn)
+		if err != nil {
+			printError(err)
+			os.Exit(exitDiffError)
+		}
+
 		options, err := loadDiffArgs(args)
 		if err != nil {
 			printError(err)
-			os.Exit(1)
+			os.Exit(exitDiffError)
 		}
 
 		err = c.Load(configPath, options...)
 		if err != nil {
 			printError(err)
-			os.Exit(1)
+			os.Exit(exitDiffError)
 		}
+		printWarnings(c)
 
 		s, err := datasource.Analyze(c.DSN)
 		if err != nil {
 			printError(err)
-			os.Exit(1)
+			os.Exit(exitDiffError)
 		}
 
 		err = c.ModifySchema(s)
 		if err != nil {
 			printError(err)
-			os.Exit(1)
+			os.Exit(exitDiffError)
 		}
 
-		diff, err := md.Diff(s, c)
+		diffs, err := md.DiffFiles(s, c)
 		if err != nil {
 			printError(err)
-			os.Exit(2)
+			os.Exit(exitDiffError)
 		}
-		fmt.Print(diff)
-		if diff != "" {
-			os.Exit(1)
+
+		switch diffFormat {
+		case "text":
+			for _, d := range diffs {
+				fmt.Print(d.String())
+			}
+		case "json":
+			hunks := []*md.Hunk{}
+			for _, d := range diffs {
+				hunks = append(hunks, d.Hunks...)
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err := encoder.Encode(hunks)
+			if err != nil {
+				printError(err)
+				os.Exit(exitDiffError)
+			}
+		default:
+			printError(fmt.Errorf("unsupported format '%s'", diffFormat))
+			os.Exit(exitDiffError)
 		}
+
+		os.Exit(diffExitStatus(diffs, failOn))
 	},
 }
 
+// diffExitStatus return exit status by the most severe kind of difference in failOn
+func diffExitStatus(diffs []*md.FileDiff, failOn []string) int {
+	kinds := map[string]bool{}
+	for _, d := range diffs {
+		for _, h := range d.Hunks {
+			for _, f := range failOn {
+				if h.Kind == f {
+					kinds[h.Kind] = true
+				}
+			}
+		}
+	}
+	switch {
+	case kinds[md.DiffKindDoc]:
+		return exitDiffDocStale
+	case kinds[md.DiffKindER]:
+		return exitDiffERStale
+	case kinds[md.DiffKindComment]:
+		return exitDiffCommentStale
+	}
+	return 0
+}
+
+// validateFailOn validate the kinds of difference in failOn
+func validateFailOn(failOn []string) error {
+	kinds := []string{md.DiffKindDoc, md.DiffKindComment, md.DiffKindER}
+	for _, f := range failOn {
+		valid := false
+		for _, k := range kinds {
+			if f == k {
+				valid = true
+			}
+		}
+		if !valid {
+			return errors.New(fmt.Sprintf("invalid --fail-on '%s'. kinds of difference are [%s]", f, strings.Join(kinds, ", ")))
+		}
+	}
+	return nil
+}
+
 func loadDiffArgs(args []string) ([]config.Option, error) {
 	options := []config.Option{}
 	if len(args) > 2 {
 		return options, errors.WithStack(errors.New("too many arguments"))
 	}
+	if profile != "" {
+		options = append(options, config.Profile(profile))
+	}
 	if adjust {
 		options = append(options, config.Adjust(adjust))
 	}
@@ -111,7 +196,10 @@ func init() {
 	rootCmd.AddCommand(diffCmd)
 	diffCmd.Flags().BoolVarP(&sort, "sort", "", false, "sort")
 	diffCmd.Flags().StringVarP(&configPath, "config", "c", "", "config file path")
+	diffCmd.Flags().StringVarP(&profile, "profile", "", "", "profile in config file ( default: $TBLS_PROFILE )")
 	diffCmd.Flags().StringVarP(&erFormat, "er-format", "t", "", fmt.Sprintf("ER diagrams output format [png, svg, jpg, ...]. default: %s", config.DefaultERFormat))
 	diffCmd.Flags().BoolVarP(&adjust, "adjust-table", "j", false, "adjust column width of table")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "", "text", "diff output format [text, json]")
+	diffCmd.Flags().StringSliceVarP(&failOn, "fail-on", "", []string{md.DiffKindDoc, md.DiffKindComment, md.DiffKindER}, "kinds of difference that fail the command [doc, comment, er]")
 	diffCmd.Flags().StringVarP(&additionalDataPath, "add", "a", "", "additional schema data path (deprecated, use `config`)")
 }
@@ -23,7 +23,7 @@ package cmd
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -32,6 +32,8 @@ import (
 	"github.com/Melsoft-Games/tbls/config"
 	"github.com/Melsoft-Games/tbls/datasource"
 	"github.com/Melsoft-Games/tbls/output/dot"
+	"github.com/Melsoft-Games/tbls/output/er"
+	"github.com/Melsoft-Games/tbls/output/html"
 	"github.com/Melsoft-Games/tbls/output/md"
 	"github.com/Melsoft-Games/tbls/schema"
 	"github.com/pkg/errors"
@@ -41,11 +43,14 @@ import (
 // withoutER
 var withoutER bool
 
+// docFormat is a option that document format
+var docFormat string
+
 // docCmd represents the doc command
 var docCmd = &cobra.Command{
 	Use:   "doc [DSN] [DOC_PATH]",
 	Short: "document a database",
-	Long:  `'tbls doc' analyzes a database and generate document in GitHub Friendly Markdown format.`,
+	Long:  `'tbls doc' analyzes a database and generate document in GitHub Friendly Markdown format ( or static HTML site ).`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c, err := config.NewConfig()
 		if err != nil {
@@ -69,6 +74,7 @@ var docCmd = &cobra.Command{
 			printError(err)
 			os.Exit(1)
 		}
+		printWarnings(c)
 
 		s, err := datasource.Analyze(c.DSN)
 		if err != nil {
@@ -82,19 +88,26 @@ var docCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if !c.ER.Skip {
-			_, err = exec.Command("which", "dot").Output() // #nosec
-			if err == nil {
-				err := withDot(s, c, force)
-				if err != nil {
-					printError(err)
-					os.Exit(1)
+		switch docFormat {
+		case "md":
+			if !c.ER.Skip && c.ER.Format != config.ERFormatMermaid {
+				_, err = exec.Command("which", "dot").Output() // #nosec
+				graphviz := err == nil
+				if graphviz || er.Supported(c.ER.Format) {
+					err := withDot(s, c, force, graphviz)
+					if err != nil {
+						printError(err)
+						os.Exit(1)
+					}
 				}
 			}
+			err = md.Output(s, c, force)
+		case "html":
+			err = html.Output(s, c, force)
+		default:
+			err = fmt.Errorf("unsupported format '%s'", docFormat)
 		}
 
-		err = md.Output(s, c, force)
-
 		if err != nil {
 			printError(err)
 			os.Exit(1)
@@ -102,7 +115,9 @@ var docCmd = &cobra.Command{
 	},
 }
 
-func withDot(s *schema.Schema, c *config.Config, force bool) error {
+// withDot output ER diagrams using Graphviz `dot` command.
+// When Graphviz is not available, ER diagrams are rendered by the native renderer ( svg and png only ).
+func withDot(s *schema.Schema, c *config.Config, force, graphviz bool) error {
 	erFormat := c.ER.Format
 	outputPath := c.DocPath
 	fullPath, err := filepath.Abs(outputPath)
@@ -119,78 +134,111 @@ func withDot(s *schema.Schema, c *config.Config, force bool) error {
 		return errors.WithStack(err)
 	}
 
-	dotFormatOption := fmt.Sprintf("-T%s", erFormat)
-	erFileName := fmt.Sprintf("schema.%s", erFormat)
-
-	fmt.Printf("%s\n", filepath.Join(outputPath, erFileName))
-	tmpfile, _ := ioutil.TempFile("", "tblstmp")
-	cmd := exec.Command("dot", dotFormatOption, "-o", filepath.Clean(filepath.Join(fullPath, erFileName)), tmpfile.Name()) // #nosec
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
 	dot := dot.NewDot(c)
+	var native *er.ER
+	if !graphviz {
+		native = er.NewER(c, erFormat)
+	}
 
-	err = dot.OutputSchema(tmpfile, s)
+	err = outputER(outputPath, fullPath, "schema", erFormat, func(wr io.Writer) error {
+		return dot.OutputSchema(wr, s)
+	}, func(wr io.Writer) error {
+		return native.OutputSchema(wr, s)
+	}, graphviz)
 	if err != nil {
-		_ = tmpfile.Close()
-		_ = os.Remove(tmpfile.Name())
 		return err
 	}
-	err = tmpfile.Close()
-	if err != nil {
-		_ = os.Remove(tmpfile.Name())
-		return errors.WithStack(err)
-	}
-	err = cmd.Run()
-	if err != nil {
-		_ = os.Remove(tmpfile.Name())
-		return errors.WithStack(errors.Wrap(err, stderr.String()))
-	}
-	err = os.Remove(tmpfile.Name())
-	if err != nil {
-		return errors.WithStack(err)
-	}
-
-	// tables
-	for _, t := range s.Tables {
-		erFileName := fmt.Sprintf("%s.%s", t.Name, erFormat)
-
-		fmt.Printf("%s\n", filepath.Join(outputPath, erFileName))
-		tmpfile, _ := ioutil.TempFile("", "tblstmp")
-		c := exec.Command("dot", dotFormatOption, "-o", filepath.Join(fullPath, erFileName), tmpfile.Name()) // #nosec
-		var stderr bytes.Buffer
-		c.Stderr = &stderr
 
-		err = dot.OutputTable(tmpfile, t)
+	// viewpoints
+	for _, v := range c.Viewpoints {
+		vs, err := v.Schema(s)
 		if err != nil {
-			_ = tmpfile.Close()
-			_ = os.Remove(tmpfile.Name())
 			return err
 		}
-		err = tmpfile.Close()
+		err = outputER(outputPath, fullPath, v.FileName(), erFormat, func(wr io.Writer) error {
+			return dot.OutputSchema(wr, vs)
+		}, func(wr io.Writer) error {
+			return native.OutputSchema(wr, vs)
+		}, graphviz)
 		if err != nil {
-			_ = os.Remove(tmpfile.Name())
-			return errors.WithStack(err)
+			return err
 		}
-		err = c.Run()
-		if err != nil {
-			_ = os.Remove(tmpfile.Name())
-			return errors.WithStack(errors.Wrap(err, stderr.String()))
+	}
+
+	// tables
+	for _, t := range s.Tables {
+		if t.External {
+			continue
 		}
-		err = os.Remove(tmpfile.Name())
+		t := t
+		err = outputER(outputPath, fullPath, t.FileName(), erFormat, func(wr io.Writer) error {
+			return dot.OutputTable(wr, t)
+		}, func(wr io.Writer) error {
+			return native.OutputTable(wr, t)
+		}, graphviz)
 		if err != nil {
-			return errors.WithStack(err)
+			return err
 		}
 	}
 
 	return nil
 }
 
+// outputER write dot source to `name.dot` and generate ER diagram from it.
+// The dot source is kept so that `tbls diff` can detect stale ER diagrams.
+// Without Graphviz, ER diagram is written by render instead.
+func outputER(outputPath, fullPath, name, erFormat string, write, render func(io.Writer) error, graphviz bool) error {
+	dotFileName := fmt.Sprintf("%s.dot", name)
+	erFileName := fmt.Sprintf("%s.%s", name, erFormat)
+
+	fmt.Printf("%s\n", filepath.Join(outputPath, dotFileName))
+	err := writeFile(filepath.Join(fullPath, dotFileName), write)
+	if err != nil {
+		return err
+	}
+	if erFormat == "dot" {
+		return nil
+	}
+
+	fmt.Printf("%s\n", filepath.Join(outputPath, erFileName))
+	if !graphviz {
+		return writeFile(filepath.Join(fullPath, erFileName), render)
+	}
+	cmd := exec.Command("dot", fmt.Sprintf("-T%s", erFormat), "-o", filepath.Clean(filepath.Join(fullPath, erFileName)), filepath.Join(fullPath, dotFileName)) // #nosec
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return errors.WithStack(errors.Wrap(err, stderr.String()))
+	}
+	return nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = write(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 func loadDocArgs(args []string) ([]config.Option, error) {
 	options := []config.Option{}
 	if len(args) > 2 {
 		return options, errors.WithStack(errors.New("too many arguments"))
 	}
+	if profile != "" {
+		options = append(options, config.Profile(profile))
+	}
 	if adjust {
 		options = append(options, config.Adjust(adjust))
 	}
@@ -219,7 +267,7 @@ func outputErExists(s *schema.Schema, path string) bool {
 	}
 	// tables
 	for _, t := range s.Tables {
-		erFileName := fmt.Sprintf("%s.%s", t.Name, erFormat)
+		erFileName := fmt.Sprintf("%s.%s", t.FileName(), erFormat)
 		if _, err := os.Lstat(filepath.Join(path, erFileName)); err == nil {
 			return true
 		}
@@ -232,7 +280,9 @@ func init() {
 	docCmd.Flags().BoolVarP(&force, "force", "f", false, "force")
 	docCmd.Flags().BoolVarP(&sort, "sort", "", false, "sort")
 	docCmd.Flags().StringVarP(&configPath, "config", "c", "", "config file path")
-	docCmd.Flags().StringVarP(&erFormat, "er-format", "t", "", fmt.Sprintf("ER diagrams output format [png, svg, jpg, ...]. default: %s", config.DefaultERFormat))
+	docCmd.Flags().StringVarP(&profile, "profile", "", "", "profile in config file ( default: $TBLS_PROFILE )")
+	docCmd.Flags().StringVarP(&erFormat, "er-format", "t", "", fmt.Sprintf("ER diagrams output format [png, svg, jpg, mermaid, ...]. default: %s", config.DefaultERFormat))
+	docCmd.Flags().StringVarP(&docFormat, "format", "", "md", "document format [md, html]")
 	docCmd.Flags().BoolVarP(&withoutER, "without-er", "", false, "no generate ER diagrams")
 	docCmd.Flags().BoolVarP(&adjust, "adjust-table", "j", false, "adjust column width of table")
 	docCmd.Flags().StringVarP(&additionalDataPath, "add", "a", "", "additional schema data path (deprecated, use `config`)")
@@ -0,0 +1,104 @@
+// Copyright © 2019 Ken'ichiro Oyama <k1lowxb@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/datasource"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initPath    string
+	passwordEnv string
+)
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init [DSN]",
+	Short: "generate starter config file from a database",
+	Long: `'tbls init' analyzes a database and generates starter config file ( .tbls.yml ).
+The password in DSN is replaced with the reference to the environment variable.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dsn, err := loadInitArgs(args)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		if _, err := os.Stat(initPath); err == nil && !force {
+			printError(errors.New(fmt.Sprintf("%s already exists. use --force to overwrite", initPath)))
+			os.Exit(1)
+		}
+
+		s, err := datasource.Analyze(dsn)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		b, err := config.Scaffold(dsn, passwordEnv, s)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		err = ioutil.WriteFile(initPath, b, 0644) // #nosec
+		if err != nil {
+			printError(errors.WithStack(err))
+			os.Exit(1)
+		}
+		fmt.Printf("%s\n", initPath)
+		for _, d := range dsn {
+			if config.MaskDSN(d, passwordEnv) != d {
+				fmt.Printf("\nSet the database password to $%s\n", passwordEnv)
+				break
+			}
+		}
+	},
+}
+
+func loadInitArgs(args []string) ([]string, error) {
+	if len(args) > 1 {
+		return nil, errors.WithStack(errors.New("too many arguments"))
+	}
+	dsn := os.Getenv("TBLS_DSN")
+	if len(args) == 1 {
+		dsn = args[0]
+	}
+	if dsn == "" {
+		return nil, errors.WithStack(errors.New("no DSN. specify DSN as an argument or $TBLS_DSN"))
+	}
+	return strings.Split(dsn, ";"), nil
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVarP(&initPath, "out", "o", ".tbls.yml", "output config file path")
+	initCmd.Flags().StringVarP(&passwordEnv, "password-env", "", config.DefaultPasswordEnv, "environment variable referred instead of the password in DSN")
+	initCmd.Flags().BoolVarP(&force, "force", "f", false, "overwrite existing config file")
+}
@@ -56,6 +56,7 @@ var lintCmd = &cobra.Command{
 			printError(err)
 			os.Exit(1)
 		}
+		printWarnings(c)
 
 		s, err := datasource.Analyze(c.DSN)
 		if err != nil {
@@ -72,12 +73,20 @@ var lintCmd = &cobra.Command{
 		l := reflect.Indirect(reflect.ValueOf(c.Lint))
 		t := l.Type()
 
+		// external tables are not linted
+		exclude := append([]string{}, c.LintExclude...)
+		for _, t := range s.Tables {
+			if t.External {
+				exclude = append(exclude, t.Name)
+			}
+		}
+
 		ruleWarns := []config.RuleWarn{}
 		for i := 0; i < t.NumField(); i++ {
 			var v config.Rule
 			r := l.Field(i)
 			v = r.Interface().(config.Rule)
-			ruleWarns = append(ruleWarns, v.Check(s, c.LintExclude)...)
+			ruleWarns = append(ruleWarns, v.Check(s, exclude)...)
 		}
 		if len(ruleWarns) > 0 {
 			for _, warn := range ruleWarns {
@@ -94,6 +103,9 @@ func loadLintArgs(args []string) ([]config.Option, error) {
 	if len(args) > 2 {
 		return options, errors.WithStack(errors.New("too many arguments"))
 	}
+	if profile != "" {
+		options = append(options, config.Profile(profile))
+	}
 	if len(args) == 2 {
 		options = append(options, config.DSN(strings.Split(args[0], ";")))
 		options = append(options, config.DocPath(args[1]))
@@ -107,4 +119,5 @@ func loadLintArgs(args []string) ([]config.Option, error) {
 func init() {
 	rootCmd.AddCommand(lintCmd)
 	lintCmd.Flags().StringVarP(&configPath, "config", "c", "", "config file path")
+	lintCmd.Flags().StringVarP(&profile, "profile", "", "", "profile in config file ( default: $TBLS_PROFILE )")
 }
@@ -23,19 +23,31 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 
 	"github.com/Melsoft-Games/tbls/config"
 	"github.com/Melsoft-Games/tbls/datasource"
 	"github.com/Melsoft-Games/tbls/output"
+	"github.com/Melsoft-Games/tbls/output/avro"
 	tbls_config "github.com/Melsoft-Games/tbls/output/config"
+	"github.com/Melsoft-Games/tbls/output/dbml"
+	"github.com/Melsoft-Games/tbls/output/ddl"
 	"github.com/Melsoft-Games/tbls/output/dot"
+	"github.com/Melsoft-Games/tbls/output/er"
+	"github.com/Melsoft-Games/tbls/output/golang"
+	"github.com/Melsoft-Games/tbls/output/html"
 	"github.com/Melsoft-Games/tbls/output/json"
+	"github.com/Melsoft-Games/tbls/output/jsonschema"
 	"github.com/Melsoft-Games/tbls/output/md"
+	"github.com/Melsoft-Games/tbls/output/mermaid"
 	"github.com/Melsoft-Games/tbls/output/plantuml"
+	"github.com/Melsoft-Games/tbls/output/proto"
+	"github.com/Melsoft-Games/tbls/output/typescript"
 	"github.com/Melsoft-Games/tbls/output/xlsx"
 	"github.com/Melsoft-Games/tbls/output/yaml"
+	"github.com/Melsoft-Games/tbls/schema"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -44,6 +56,9 @@ var (
 	format    string
 	outPath   string
 	tableName string
+	dialect   string
+	labels    []string
+	inPlace   bool
 )
 
 // outCmd represents the doc command
@@ -74,6 +89,7 @@ var outCmd = &cobra.Command{
 			printError(err)
 			os.Exit(1)
 		}
+		printWarnings(c)
 
 		s, err := datasource.Analyze(c.DSN)
 		if err != nil {
@@ -87,6 +103,8 @@ var outCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		s.FilterTablesByLabels(labels)
+
 		var o output.Output
 
 		switch format {
@@ -97,11 +115,35 @@ var outCmd = &cobra.Command{
 		case "dot":
 			o = dot.NewDot(c)
 		case "md":
-			o = md.NewMd(c, false)
+			o = md.NewMd(c, false, s.Driver)
+		case "html":
+			o = html.NewHTML(c, false)
 		case "xlsx":
-			o = new(xlsx.Xlsx)
+			o = xlsx.NewXlsx(c, s.Driver)
 		case "plantuml":
 			o = plantuml.NewPlantUML(c)
+		case "mermaid":
+			o = mermaid.NewMermaid(c)
+		case "dbml":
+			o = dbml.NewDBML(c)
+		case "jsonschema":
+			o = jsonschema.NewJSONSchema(c, s.Driver)
+		case "avro":
+			o = avro.NewAvro(c, s.Driver)
+		case "proto":
+			o = proto.NewProto(c, s.Driver)
+		case "ddl":
+			if dialect != "" && !ddl.Supported(dialect) {
+				printError(fmt.Errorf("unsupported dialect '%s'", dialect))
+				os.Exit(1)
+			}
+			o = ddl.NewDDL(c, s.Driver, dialect)
+		case "go":
+			o = golang.NewGolang(c, s.Driver)
+		case "typescript":
+			o = typescript.NewTypeScript(c, s.Driver)
+		case "svg", "png":
+			o = er.NewER(c, format)
 		case "config":
 			o = tbls_config.NewConfig(c)
 		default:
@@ -109,6 +151,15 @@ var outCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if inPlace {
+			err = updateConfigInPlace(c, s)
+			if err != nil {
+				printError(err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		var wr io.Writer
 		if outPath != "" {
 			file, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE, 0666) // #nosec
@@ -140,11 +191,40 @@ var outCmd = &cobra.Command{
 	},
 }
 
+// updateConfigInPlace add missing comment stubs into the config file
+func updateConfigInPlace(c *config.Config, s *schema.Schema) error {
+	if format != "config" {
+		return errors.New("--in-place is only for `-t config`")
+	}
+	if outPath != "" {
+		return errors.New("--in-place can not be used with --out")
+	}
+	if c.Path() == "" {
+		return errors.New("no config file to update")
+	}
+	info, err := os.Stat(c.Path())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	buf, err := ioutil.ReadFile(c.Path())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	updated, err := tbls_config.NewConfig(c).UpdateComments(buf, s)
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(ioutil.WriteFile(c.Path(), updated, info.Mode()))
+}
+
 func loadOutArgs(args []string) ([]config.Option, error) {
 	options := []config.Option{}
 	if len(args) > 1 {
 		return options, errors.WithStack(errors.New("too many arguments"))
 	}
+	if profile != "" {
+		options = append(options, config.Profile(profile))
+	}
 	if sort {
 		options = append(options, config.Sort(sort))
 	}
@@ -158,8 +238,12 @@ func init() {
 	rootCmd.AddCommand(outCmd)
 	outCmd.Flags().BoolVarP(&sort, "sort", "", false, "sort")
 	outCmd.Flags().StringVarP(&configPath, "config", "c", "", "config file path")
+	outCmd.Flags().StringVarP(&profile, "profile", "", "", "profile in config file ( default: $TBLS_PROFILE )")
 	outCmd.Flags().StringVarP(&format, "format", "t", "json", "output format")
 	outCmd.Flags().StringVarP(&outPath, "out", "o", "", "output file path")
+	outCmd.Flags().BoolVarP(&inPlace, "in-place", "", false, "add missing comment stubs into config file in place ( only for -t config )")
 	outCmd.Flags().StringVar(&tableName, "table", "", "table name")
+	outCmd.Flags().StringArrayVar(&labels, "label", []string{}, "output only tables that have the label on the table or its columns ( name or name:value )")
+	outCmd.Flags().StringVar(&dialect, "dialect", "", "target dialect of DDL ( postgres, mysql, sqlite, bigquery )")
 	outCmd.Flags().StringVarP(&additionalDataPath, "add", "a", "", "additional schema data path (deprecated, use `config`)")
 }
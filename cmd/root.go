@@ -25,6 +25,7 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/Melsoft-Games/tbls/config"
 	"github.com/spf13/cobra"
 )
 
@@ -40,6 +41,9 @@ var sort bool
 // configPath is a config file path
 var configPath string
 
+// profile is a profile name in config file
+var profile string
+
 // additionalDataPath is a additional data path
 var additionalDataPath string
 
@@ -64,6 +68,13 @@ func Execute() {
 
 func init() {}
 
+// printWarnings print warnings on loading config file to stderr so that they are not mixed with output
+func printWarnings(c *config.Config) {
+	for _, w := range c.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+}
+
 func printError(err error) {
 	env := os.Getenv("DEBUG")
 	debug, _ := strconv.ParseBool(env)
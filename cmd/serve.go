@@ -0,0 +1,100 @@
+// Copyright © 2020 Ken'ichiro Oyama <k1lowxb@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/server"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addr  string
+	watch bool
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve [DSN]",
+	Short: "serve database document on local web server",
+	Long:  `'tbls serve' analyzes a database and serve document on local web server.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		options, err := loadServeArgs(args)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		srv := server.NewServer(configPath, options...)
+		err = srv.Analyze()
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+		if watch {
+			go srv.Watch(2 * time.Second)
+		}
+
+		fmt.Printf("Serving on http://%s/\n", addr)
+		err = http.ListenAndServe(addr, srv)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func loadServeArgs(args []string) ([]config.Option, error) {
+	options := []config.Option{}
+	if len(args) > 1 {
+		return options, errors.WithStack(errors.New("too many arguments"))
+	}
+	if profile != "" {
+		options = append(options, config.Profile(profile))
+	}
+	if adjust {
+		options = append(options, config.Adjust(adjust))
+	}
+	if sort {
+		options = append(options, config.Sort(sort))
+	}
+	if len(args) == 1 {
+		options = append(options, config.DSN(strings.Split(args[0], ";")))
+	}
+	return options, nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().BoolVarP(&sort, "sort", "", false, "sort")
+	serveCmd.Flags().StringVarP(&configPath, "config", "c", "", "config file path")
+	serveCmd.Flags().StringVarP(&profile, "profile", "", "", "profile in config file ( default: $TBLS_PROFILE )")
+	serveCmd.Flags().BoolVarP(&adjust, "adjust-table", "j", false, "adjust column width of table")
+	serveCmd.Flags().StringVarP(&addr, "addr", "", "localhost:8080", "listen address")
+	serveCmd.Flags().BoolVarP(&watch, "watch", "w", true, "re-analyze when the config file is changed")
+}
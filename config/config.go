@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
-	"io/ioutil"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -16,29 +15,91 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-const defaultConfigFilePath = ".tbls.yml"
+// DefaultConfigFilePath is default config file path
+const DefaultConfigFilePath = ".tbls.yml"
+
 const defaultDocPath = "dbdoc"
 
 // DefaultERFormat is default ER diagram format
 const DefaultERFormat = "png"
 
+// ERFormatMermaid is ER diagram format that embeds Mermaid erDiagram into Markdown document
+const ERFormatMermaid = "mermaid"
+
+// Modes of relations between excluded tables and remaining tables
+const (
+	// ExcludeRelationsError fails to exclude the table that is related by remaining tables ( default )
+	ExcludeRelationsError = "error"
+	// ExcludeRelationsDrop drops the relations to excluded tables
+	ExcludeRelationsDrop = "drop"
+	// ExcludeRelationsExternal keeps the related excluded tables as external table stubs
+	ExcludeRelationsExternal = "external"
+)
+
 // Config is tbls config
 type Config struct {
-	DSN         []string             `yaml:"dsn"`
-	DocPath     string               `yaml:"docPath"`
-	Format      Format               `yaml:"format"`
-	ER          ER                   `yaml:"er"`
-	Exclude     []string             `yaml:"exclude"`
-	Lint        Lint                 `yaml:"lint"`
-	LintExclude []string             `yaml:"lintExclude"`
-	Relations   []AdditionalRelation `yaml:"relations"`
-	Comments    []AdditionalComment  `yaml:"comments"`
+	DSN                    []string               `yaml:"dsn"`
+	DocPath                string                 `yaml:"docPath"`
+	Format                 Format                 `yaml:"format"`
+	ER                     ER                     `yaml:"er"`
+	Exclude                []string               `yaml:"exclude"`
+	Include                []string               `yaml:"include,omitempty"`
+	ExcludeRelations       string                 `yaml:"excludeRelations,omitempty"`
+	Lint                   Lint                   `yaml:"lint"`
+	LintExclude            []string               `yaml:"lintExclude"`
+	Relations              []AdditionalRelation   `yaml:"relations"`
+	Comments               []AdditionalComment    `yaml:"comments"`
+	DetectVirtualRelations DetectVirtualRelations `yaml:"detectVirtualRelations,omitempty"`
+	InferRelations         InferRelations         `yaml:"inferRelations,omitempty"`
+	Gen                    Gen                    `yaml:"gen,omitempty"`
+	Templates              Templates              `yaml:"templates,omitempty"`
+	Labels                 Labels                 `yaml:"labels,omitempty"`
+	Viewpoints             []Viewpoint            `yaml:"viewpoints,omitempty"`
+	profile                string
+	path                   string
+	strict                 bool
+	warnings               []string
 }
 
 // Format is document format setting
 type Format struct {
-	Adjust bool `yaml:"adjust"`
-	Sort   bool `yaml:"sort"`
+	Adjust           bool           `yaml:"adjust"`
+	Sort             bool           `yaml:"sort"`
+	Columns          []ColumnFormat `yaml:"columns,omitempty"`
+	HideEmptyColumns *bool          `yaml:"hideEmptyColumns,omitempty"`
+}
+
+// ColumnFormat is the column of "Columns" table in table document.
+// It is a built-in column ( e.g. `Type` ) or a custom column that has header and value template.
+type ColumnFormat struct {
+	Name   string `yaml:"name,omitempty"`
+	Header string `yaml:"header,omitempty"`
+	Value  string `yaml:"value,omitempty"`
+}
+
+// UnmarshalYAML unmarshal built-in column name ( `- Type` ) or column setting ( `- header: ...` )
+func (f *ColumnFormat) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		f.Name = name
+		return nil
+	}
+	type raw ColumnFormat
+	r := raw{}
+	if err := unmarshal(&r); err != nil {
+		return err
+	}
+	*f = ColumnFormat(r)
+	return nil
+}
+
+// MarshalYAML marshal built-in column as its name
+func (f ColumnFormat) MarshalYAML() (interface{}, error) {
+	if f.Header == "" && f.Value == "" {
+		return f.Name, nil
+	}
+	type raw ColumnFormat
+	return raw(f), nil
 }
 
 // ER is er diagram setting
@@ -48,6 +109,59 @@ type ER struct {
 	Comment bool   `yaml:"comment"`
 }
 
+// Gen is code generation setting for `tbls out -t go` and `tbls out -t typescript`
+type Gen struct {
+	Go         GenGo         `yaml:"go"`
+	TypeScript GenTypeScript `yaml:"typescript"`
+}
+
+// GenGo is Go code generation setting
+type GenGo struct {
+	Package  string   `yaml:"package"`
+	Nullable string   `yaml:"nullable"`
+	Tags     []string `yaml:"tags"`
+}
+
+// GenTypeScript is TypeScript code generation setting
+type GenTypeScript struct {
+	Module string `yaml:"module"`
+}
+
+// Templates is custom template file setting. Empty path means the built-in template.
+type Templates struct {
+	MD   MDTemplates   `yaml:"md,omitempty"`
+	Dot  DotTemplates  `yaml:"dot,omitempty"`
+	PUML PUMLTemplates `yaml:"puml,omitempty"`
+}
+
+// MDTemplates is custom template files for Markdown document
+type MDTemplates struct {
+	Index     string `yaml:"index,omitempty"`
+	Table     string `yaml:"table,omitempty"`
+	Viewpoint string `yaml:"viewpoint,omitempty"`
+}
+
+// DotTemplates is custom template files for dot
+type DotTemplates struct {
+	Schema string `yaml:"schema,omitempty"`
+	Table  string `yaml:"table,omitempty"`
+}
+
+// PUMLTemplates is custom template files for PlantUML
+type PUMLTemplates struct {
+	Schema string `yaml:"schema,omitempty"`
+	Table  string `yaml:"table,omitempty"`
+}
+
+// Viewpoint is a named subset of tables that has its own document and ER diagram.
+// Tables are selected by table names, glob patterns ( e.g. `payment_*` ) or labels.
+type Viewpoint struct {
+	Name   string   `yaml:"name"`
+	Desc   string   `yaml:"desc,omitempty"`
+	Tables []string `yaml:"tables,omitempty"`
+	Labels []string `yaml:"labels,omitempty"`
+}
+
 // AdditionalRelation is the struct for table relation from yaml
 type AdditionalRelation struct {
 	Table         string   `yaml:"table"`
@@ -57,11 +171,54 @@ type AdditionalRelation struct {
 	Def           string   `yaml:"def"`
 }
 
+// Labels is label setting
+type Labels struct {
+	FromComment bool `yaml:"fromComment,omitempty"`
+}
+
 // AdditionalComment is the struct for table relation from yaml
 type AdditionalComment struct {
-	Table          string            `yaml:"table"`
-	TableComment   string            `yaml:"tableComment"`
-	ColumnComments map[string]string `yaml:"columnComments"`
+	Table          string                       `yaml:"table"`
+	TableComment   string                       `yaml:"tableComment"`
+	ColumnComments map[string]string            `yaml:"columnComments"`
+	Labels         []AdditionalLabel            `yaml:"labels,omitempty"`
+	ColumnLabels   map[string][]AdditionalLabel `yaml:"columnLabels,omitempty"`
+}
+
+// AdditionalLabel is the struct for table/column label from yaml.
+// It is a tag ( `- pii` ) or a single key-value ( `- owner: team-growth` ).
+type AdditionalLabel struct {
+	Name  string
+	Value string
+}
+
+// UnmarshalYAML unmarshal label tag or key-value
+func (l *AdditionalLabel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		l.Name = name
+		return nil
+	}
+	m := yaml.MapSlice{}
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	if len(m) != 1 {
+		return errors.New("failed to load label: label should be `name` or `name: value`")
+	}
+	l.Name = fmt.Sprintf("%v", m[0].Key)
+	if m[0].Value != nil {
+		l.Value = fmt.Sprintf("%v", m[0].Value)
+	}
+	return nil
+}
+
+// MarshalYAML marshal label as tag or key-value
+func (l AdditionalLabel) MarshalYAML() (interface{}, error) {
+	if l.Value == "" {
+		return l.Name, nil
+	}
+	return yaml.MapSlice{{Key: l.Name, Value: l.Value}}, nil
 }
 
 // Option function change Config
@@ -121,6 +278,14 @@ func ERFormat(erFormat string) Option {
 	}
 }
 
+// Strict return Option that makes unknown keys in config file errors instead of warnings
+func Strict(strict bool) Option {
+	return func(c *Config) error {
+		c.strict = strict
+		return nil
+	}
+}
+
 // NewConfig return Config
 func NewConfig() (*Config, error) {
 	c := Config{
@@ -132,6 +297,19 @@ func NewConfig() (*Config, error) {
 
 // Load load config with all method
 func (c *Config) Load(configPath string, options ...Option) error {
+	// profile should be selected before loading config file
+	p := &Config{}
+	for _, option := range options {
+		err := option(p)
+		if err != nil {
+			return err
+		}
+	}
+	if p.profile != "" {
+		c.profile = p.profile
+	}
+	c.strict = p.strict
+
 	err := c.LoadConfigFile(configPath)
 	if err != nil {
 		return err
@@ -173,10 +351,20 @@ func (c *Config) LoadEnviron() error {
 	return nil
 }
 
+// Path return the path of the loaded config file
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Warnings return warnings on loading config file ( e.g. unknown keys )
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
 // LoadConfigFile load config file
 func (c *Config) LoadConfigFile(path string) error {
 	if path == "" {
-		path = defaultConfigFilePath
+		path = DefaultConfigFilePath
 		if _, err := os.Lstat(path); err != nil {
 			return nil
 		}
@@ -187,7 +375,17 @@ func (c *Config) LoadConfigFile(path string) error {
 		return errors.Wrap(errors.WithStack(err), "failed to load config file")
 	}
 
-	buf, err := ioutil.ReadFile(filepath.Clean(fullPath))
+	c.path = fullPath
+
+	doc, err := c.loadConfigYAML(fullPath, map[string]bool{})
+	if err != nil {
+		return errors.Wrap(err, "failed to load config file")
+	}
+	doc, err = applyProfile(doc, c.selectedProfile())
+	if err != nil {
+		return errors.Wrap(err, "failed to load config file")
+	}
+	buf, err := yaml.Marshal(doc)
 	if err != nil {
 		return errors.Wrap(errors.WithStack(err), "failed to load config file")
 	}
@@ -207,6 +405,37 @@ func (c *Config) LoadConfigFile(path string) error {
 	if err != nil {
 		return errors.Wrap(errors.WithStack(err), "failed to load config file")
 	}
+
+	// template and query log paths are relative to the config file
+	paths := []*string{
+		&c.Templates.MD.Index, &c.Templates.MD.Table, &c.Templates.MD.Viewpoint,
+		&c.Templates.Dot.Schema, &c.Templates.Dot.Table,
+		&c.Templates.PUML.Schema, &c.Templates.PUML.Table,
+	}
+	for i := range c.InferRelations.QueryLogs {
+		paths = append(paths, &c.InferRelations.QueryLogs[i])
+	}
+	for _, p := range paths {
+		if *p == "" {
+			continue
+		}
+		*p, err = parseWithEnviron(*p)
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), "failed to load config file")
+		}
+		if !filepath.IsAbs(*p) {
+			*p = filepath.Join(filepath.Dir(fullPath), *p)
+		}
+	}
+
+	err = c.validatePatterns()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config file")
+	}
+	err = c.validateViewpoints()
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "failed to load config file")
+	}
 	return nil
 }
 
@@ -235,29 +464,109 @@ func (c *Config) MergeAdditionalData(s *schema.Schema) error {
 	if err != nil {
 		return err
 	}
+	err = inferRelations(s, c.InferRelations, c.excluded)
+	if err != nil {
+		return err
+	}
+	err = detectVirtualRelations(s, c.DetectVirtualRelations, c.excluded)
+	if err != nil {
+		return err
+	}
 	err = mergeAdditionalComments(s, c.Comments)
 	if err != nil {
 		return err
 	}
+	if c.Labels.FromComment {
+		extractLabelsFromComments(s)
+	}
 	return nil
 }
 
-// ExcludeTables exclude tables from schema.Schema
+// ExcludeTables exclude tables that match names or patterns of `exclude`, or tables that do not match `include` from schema.Schema
 func (c *Config) ExcludeTables(s *schema.Schema) error {
-	for _, e := range c.Exclude {
-		for _, r := range s.Relations {
-			if r.ParentTable.Name == e {
-				return errors.New(fmt.Sprintf("failed to exclude table '%s': '%s' is related by '%s'", e, e, r.Table.Name))
-			}
+	switch c.ExcludeRelations {
+	case "", ExcludeRelationsError, ExcludeRelationsDrop, ExcludeRelationsExternal:
+	default:
+		return errors.New(fmt.Sprintf("failed to exclude tables: unsupported excludeRelations '%s'", c.ExcludeRelations))
+	}
+	excluded := map[string]bool{}
+	for _, t := range s.Tables {
+		if c.excluded(t.Name) {
+			excluded[t.Name] = true
+		}
+	}
+	externals := map[string]bool{}
+	for _, r := range s.Relations {
+		if !excluded[r.ParentTable.Name] || excluded[r.Table.Name] {
+			continue
+		}
+		switch c.ExcludeRelations {
+		case "", ExcludeRelationsError:
+			return errors.New(fmt.Sprintf("failed to exclude table '%s': '%s' is related by '%s'", r.ParentTable.Name, r.ParentTable.Name, r.Table.Name))
+		case ExcludeRelationsDrop:
+		case ExcludeRelationsExternal:
+			externals[r.ParentTable.Name] = true
 		}
-		err := excludeTableFromSchema(e, s)
+	}
+	for _, t := range s.Tables {
+		if !excluded[t.Name] || externals[t.Name] {
+			continue
+		}
+		err := excludeTableFromSchema(t.Name, s)
 		if err != nil {
-			return errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to exclude table '%s'", e))
+			return errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to exclude table '%s'", t.Name))
 		}
 	}
+	externalizeTables(s, externals)
 	return nil
 }
 
+// excluded return true if the table matches `exclude` or does not match `include`
+func (c *Config) excluded(name string) bool {
+	return (len(c.Include) > 0 && !contains(c.Include, name)) || contains(c.Exclude, name)
+}
+
+// externalizeTables make the tables stubs that have only the columns related by remaining tables
+func externalizeTables(s *schema.Schema, externals map[string]bool) {
+	if len(externals) == 0 {
+		return
+	}
+	relations := []*schema.Relation{}
+	for _, r := range s.Relations {
+		if !externals[r.Table.Name] {
+			relations = append(relations, r)
+		}
+	}
+	s.Relations = relations
+	for _, t := range s.Tables {
+		for _, c := range t.Columns {
+			childRelations := []*schema.Relation{}
+			for _, r := range c.ChildRelations {
+				if !externals[r.Table.Name] {
+					childRelations = append(childRelations, r)
+				}
+			}
+			c.ChildRelations = childRelations
+		}
+		if !externals[t.Name] {
+			continue
+		}
+		columns := []*schema.Column{}
+		for _, c := range t.Columns {
+			if len(c.ChildRelations) > 0 {
+				c.ParentRelations = nil
+				columns = append(columns, c)
+			}
+		}
+		t.Columns = columns
+		t.Indexes = nil
+		t.Constraints = nil
+		t.Triggers = nil
+		t.Def = ""
+		t.External = true
+	}
+}
+
 func excludeTableFromSchema(name string, s *schema.Schema) error {
 	// Tables
 	tables := []*schema.Table{}
@@ -269,7 +578,7 @@ func excludeTableFromSchema(name string, s *schema.Schema) error {
 			// ChildRelations
 			childRelations := []*schema.Relation{}
 			for _, r := range c.ChildRelations {
-				if r.Table.Name != name {
+				if r.Table.Name != name && r.ParentTable.Name != name {
 					childRelations = append(childRelations, r)
 				}
 			}
@@ -278,7 +587,7 @@ func excludeTableFromSchema(name string, s *schema.Schema) error {
 			// ParentRelations
 			parentRelations := []*schema.Relation{}
 			for _, r := range c.ParentRelations {
-				if r.Table.Name != name {
+				if r.Table.Name != name && r.ParentTable.Name != name {
 					parentRelations = append(parentRelations, r)
 				}
 			}
@@ -290,7 +599,7 @@ func excludeTableFromSchema(name string, s *schema.Schema) error {
 	// Relations
 	relations := []*schema.Relation{}
 	for _, r := range s.Relations {
-		if r.Table.Name != name {
+		if r.Table.Name != name && r.ParentTable.Name != name {
 			relations = append(relations, r)
 		}
 	}
@@ -299,6 +608,50 @@ func excludeTableFromSchema(name string, s *schema.Schema) error {
 	return nil
 }
 
+var viewpointFileNameRe = regexp.MustCompile(`[^\p{L}\p{N}_]+`)
+
+// FileName return the base name of the viewpoint document and ER diagram derived from the viewpoint name ( e.g. `viewpoint-table-a` for `table a` )
+func (v Viewpoint) FileName() string {
+	return fmt.Sprintf("viewpoint-%s", strings.Trim(viewpointFileNameRe.ReplaceAllString(strings.ToLower(v.Name), "-"), "-"))
+}
+
+// validateViewpoints check that viewpoints have names and that the names give distinct file names
+func (c *Config) validateViewpoints() error {
+	names := map[string]string{}
+	for i, v := range c.Viewpoints {
+		if strings.TrimSpace(v.Name) == "" {
+			return errors.New(fmt.Sprintf("viewpoints[%d].name is required", i))
+		}
+		f := v.FileName()
+		if n, ok := names[f]; ok {
+			return errors.New(fmt.Sprintf("viewpoints '%s' and '%s' have the same file name '%s'", n, v.Name, f))
+		}
+		names[f] = v.Name
+	}
+	return nil
+}
+
+// Schema return schema.Schema that has the tables of the viewpoint
+func (v Viewpoint) Schema(s *schema.Schema) (*schema.Schema, error) {
+	selected := map[*schema.Table]bool{}
+	for _, name := range v.Tables {
+		tables, err := findTables(s, name)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to load viewpoint '%s'", v.Name))
+		}
+		for _, t := range tables {
+			selected[t] = true
+		}
+	}
+	tables := []*schema.Table{}
+	for _, t := range s.Tables {
+		if selected[t] || (len(v.Labels) > 0 && t.MatchLabels(v.Labels)) {
+			tables = append(tables, t)
+		}
+	}
+	return s.Subset(v.Name, tables), nil
+}
+
 // MaskedDSN return DSN mask password
 func (c *Config) MaskedDSN() (string, error) {
 	u, err := url.Parse(c.DSN[0])
@@ -312,65 +665,140 @@ func (c *Config) MaskedDSN() (string, error) {
 
 func mergeAdditionalRelations(s *schema.Schema, relations []AdditionalRelation) error {
 	for _, r := range relations {
-		relation := &schema.Relation{
-			Virtual: true,
-		}
-		if r.Def != "" {
-			relation.Def = r.Def
-		} else {
-			relation.Def = "Additional Relation"
-		}
-		var err error
-		relation.Table, err = s.FindTableByName(r.Table)
+		tables, err := findTables(s, r.Table)
 		if err != nil {
 			return errors.Wrap(err, "failed to add relation")
 		}
-		for _, c := range r.Columns {
-			column, err := relation.Table.FindColumnByName(c)
-			if err != nil {
-				return errors.Wrap(err, "failed to add relation")
-			}
-			relation.Columns = append(relation.Columns, column)
-			column.ParentRelations = append(column.ParentRelations, relation)
-		}
-		relation.ParentTable, err = s.FindTableByName(r.ParentTable)
+		parents, err := findTables(s, r.ParentTable)
 		if err != nil {
 			return errors.Wrap(err, "failed to add relation")
 		}
-		for _, c := range r.ParentColumns {
-			column, err := relation.ParentTable.FindColumnByName(c)
-			if err != nil {
-				return errors.Wrap(err, "failed to add relation")
-			}
-			relation.ParentColumns = append(relation.ParentColumns, column)
-			column.ChildRelations = append(column.ChildRelations, relation)
+		if len(parents) != 1 {
+			return errors.New(fmt.Sprintf("failed to add relation: parentTable '%s' matches %d tables", r.ParentTable, len(parents)))
 		}
+		for _, t := range tables {
+			relation := &schema.Relation{
+				Table:       t,
+				ParentTable: parents[0],
+				Virtual:     true,
+			}
+			if r.Def != "" {
+				relation.Def = r.Def
+			} else {
+				relation.Def = "Additional Relation"
+			}
+			// columns of the tables that match pattern may not exist
+			strict := !isPattern(r.Table)
+			columns := []*schema.Column{}
+			for _, c := range r.Columns {
+				column, err := t.FindColumnByName(c)
+				if err != nil {
+					if !strict {
+						break
+					}
+					return errors.Wrap(err, "failed to add relation")
+				}
+				columns = append(columns, column)
+			}
+			if len(columns) != len(r.Columns) {
+				continue
+			}
+			parentColumns := []*schema.Column{}
+			for _, c := range r.ParentColumns {
+				column, err := relation.ParentTable.FindColumnByName(c)
+				if err != nil {
+					return errors.Wrap(err, "failed to add relation")
+				}
+				parentColumns = append(parentColumns, column)
+			}
+			for _, column := range columns {
+				relation.Columns = append(relation.Columns, column)
+				column.ParentRelations = append(column.ParentRelations, relation)
+			}
+			for _, column := range parentColumns {
+				relation.ParentColumns = append(relation.ParentColumns, column)
+				column.ChildRelations = append(column.ChildRelations, relation)
+			}
 
-		s.Relations = append(s.Relations, relation)
+			s.Relations = append(s.Relations, relation)
+		}
 	}
 	return nil
 }
 
 func mergeAdditionalComments(s *schema.Schema, comments []AdditionalComment) error {
 	for _, c := range comments {
-		table, err := s.FindTableByName(c.Table)
+		tables, err := findTables(s, c.Table)
 		if err != nil {
 			return errors.Wrap(err, "failed to add table comment")
 		}
-		if c.TableComment != "" {
-			table.Comment = c.TableComment
-		}
-		for c, comment := range c.ColumnComments {
-			column, err := table.FindColumnByName(c)
-			if err != nil {
-				return errors.Wrap(err, "failed to add column comment")
+		// columns of the tables that match pattern may not exist
+		strict := !isPattern(c.Table)
+		for _, table := range tables {
+			if c.TableComment != "" {
+				table.Comment = c.TableComment
+			}
+			keys := []string{}
+			for k := range c.ColumnComments {
+				keys = append(keys, k)
+			}
+			for _, k := range patternsFirst(keys) {
+				columns, err := findColumns(table, k, strict)
+				if err != nil {
+					return errors.Wrap(err, "failed to add column comment")
+				}
+				for _, column := range columns {
+					column.Comment = c.ColumnComments[k]
+				}
+			}
+			for _, l := range c.Labels {
+				table.Labels = table.Labels.Merge(l.Name, l.Value)
+			}
+			keys = []string{}
+			for k := range c.ColumnLabels {
+				keys = append(keys, k)
+			}
+			for _, k := range patternsFirst(keys) {
+				labels := c.ColumnLabels[k]
+				columns, err := findColumns(table, k, strict)
+				if err != nil {
+					return errors.Wrap(err, "failed to add column labels")
+				}
+				for _, column := range columns {
+					for _, l := range labels {
+						column.Labels = column.Labels.Merge(l.Name, l.Value)
+					}
+				}
 			}
-			column.Comment = comment
 		}
 	}
 	return nil
 }
 
+var labelInCommentRe = regexp.MustCompile(`(^|\s)@([A-Za-z_][\w\-.]*)(?::([^\s]+))?`)
+
+// extractLabelsFromComments extract `@name` and `@name:value` annotations in comments as labels,
+// and remove them from comments
+func extractLabelsFromComments(s *schema.Schema) {
+	for _, t := range s.Tables {
+		t.Comment, t.Labels = extractLabels(t.Comment, t.Labels)
+		for _, c := range t.Columns {
+			c.Comment, c.Labels = extractLabels(c.Comment, c.Labels)
+		}
+	}
+}
+
+func extractLabels(comment string, labels schema.Labels) (string, schema.Labels) {
+	for _, m := range labelInCommentRe.FindAllStringSubmatch(comment, -1) {
+		labels = labels.Merge(m[2], m[3])
+	}
+	lines := strings.Split(labelInCommentRe.ReplaceAllString(comment, "$1"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), labels
+}
+
 func parseWithEnviron(v string) (string, error) {
 	r := regexp.MustCompile(`\${\s*([^{}]+)\s*}`)
 	r2 := regexp.MustCompile(`{{([^\.])`)
@@ -1,11 +1,15 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Melsoft-Games/tbls/schema"
+	"gopkg.in/yaml.v2"
 )
 
 func TestLoadDefault(t *testing.T) {
@@ -46,6 +50,265 @@ func TestLoadConfigFile(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFileExtends(t *testing.T) {
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "extends_test_tbls.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"dsn", c.DSN, []string{"my://root:mypass@localhost:33306/testdb"}},
+		{"exclude", c.Exclude, []string{"migrations", "logs"}},
+		{"format.adjust", c.Format.Adjust, true},
+		{"format.columns", len(c.Format.Columns), 1},
+		{"lint.requireTableComment.enabled", c.Lint.RequireTableComment.Enabled, true},
+		{"lint.requireTableComment.exclude", c.Lint.RequireTableComment.Exclude, []string{"logs", "CamelizeTable"}},
+		{"lint.columnCount", c.Lint.ColumnCount.Max, 20},
+		{"relations", len(c.Relations), 1},
+		{"templates.md.table", c.Templates.MD.Table, filepath.Join(testdataDir(), "extends", "templates", "table.md.tmpl")},
+	}
+	for _, tt := range tests {
+		if fmt.Sprintf("%v", tt.got) != fmt.Sprintf("%v", tt.want) {
+			t.Errorf("%s: got %v\nwant %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "extends_circular_tbls.yml"))
+	if err == nil {
+		t.Error("circular extends: got nil\nwant error")
+	}
+}
+
+func TestMergeYAML(t *testing.T) {
+	base := yaml.MapSlice{}
+	override := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte("dsn: [a]\nexclude: [a]\nlintExclude: [a]\nformat:\n  adjust: true\n  sort: true\n"), &base); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte("dsn: [b]\nexclude: [b]\nlintExclude: ~\nformat:\n  sort: false\n"), &override); err != nil {
+		t.Fatal(err)
+	}
+	got, err := yaml.Marshal(mergeYAML(base, override, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "dsn:\n- b\nexclude:\n- a\n- b\nlintExclude: null\nformat:\n  adjust: true\n  sort: false\n"
+	if string(got) != want {
+		t.Errorf("got %v\nwant %v", string(got), want)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		profile     string
+		env         string
+		wantDSN     string
+		wantDocPath string
+		wantExclude string
+		wantErr     bool
+	}{
+		{"", "", "my://root:mypass@localhost:33306/testdb", "dbdoc", "[migrations]", false},
+		{"prod", "", "my://root:mypass@prod:3306/testdb", "dbdoc/prod", "[migrations tmp_*]", false},
+		{"", "staging", "my://root:mypass@staging:3306/testdb", "dbdoc/staging", "[migrations]", false},
+		{"prod", "staging", "my://root:mypass@prod:3306/testdb", "dbdoc/prod", "[migrations tmp_*]", false},
+		{"unknown", "", "", "", "", true},
+	}
+	defer os.Unsetenv("TBLS_PROFILE")
+	for _, tt := range tests {
+		os.Setenv("TBLS_PROFILE", tt.env)
+		c, err := NewConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		options := []Option{}
+		if tt.profile != "" {
+			options = append(options, Profile(tt.profile))
+		}
+		err = c.Load(filepath.Join(testdataDir(), "profiles_test_tbls.yml"), options...)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got nil\nwant error", tt.profile)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.DSN[0]; got != tt.wantDSN {
+			t.Errorf("%s %s: got %v\nwant %v", tt.profile, tt.env, got, tt.wantDSN)
+		}
+		if got := c.DocPath; got != tt.wantDocPath {
+			t.Errorf("%s %s: got %v\nwant %v", tt.profile, tt.env, got, tt.wantDocPath)
+		}
+		if got := fmt.Sprintf("%v", c.Exclude); got != tt.wantExclude {
+			t.Errorf("%s %s: got %v\nwant %v", tt.profile, tt.env, got, tt.wantExclude)
+		}
+	}
+}
+
+func TestLoadConfigFileStrict(t *testing.T) {
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(testdataDir(), "validate_test_tbls.yml")
+	unknownKeys := []string{
+		fmt.Sprintf("%s:5: field requireColumnComments not found in type config.Lint", path),
+		fmt.Sprintf("%s:14: field columnComment not found in type config.AdditionalComment", path),
+	}
+	invalidValue := fmt.Sprintf("%s:8: cannot unmarshal !!str `yes please` into bool", path)
+
+	err = c.Load(path, Strict(true))
+	if err == nil {
+		t.Fatal("got nil\nwant error")
+	}
+	for _, want := range append(unknownKeys, invalidValue) {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got %v\nwant %v", err, want)
+		}
+	}
+
+	// unknown keys are warnings unless strict
+	c, err = NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.LoadConfigFile(path)
+	if err == nil || !strings.Contains(err.Error(), invalidValue) {
+		t.Errorf("got %v\nwant %v", err, invalidValue)
+	}
+	for _, unknown := range unknownKeys {
+		if strings.Contains(err.Error(), unknown) {
+			t.Errorf("got %v\nwant only %v", err, invalidValue)
+		}
+	}
+	if got := fmt.Sprintf("%v", c.Warnings()); got != fmt.Sprintf("%v", unknownKeys) {
+		t.Errorf("got %v\nwant %v", got, unknownKeys)
+	}
+	tempDir, _ := ioutil.TempDir("", "tbls")
+	defer os.RemoveAll(tempDir)
+	path = filepath.Join(tempDir, ".tbls.yml")
+	if err := ioutil.WriteFile(path, []byte("docPath: doc\nunknownKey: true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err = NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadConfigFile(path); err != nil {
+		t.Errorf("got %v\nwant nil", err)
+	}
+	if len(c.Warnings()) != 1 {
+		t.Errorf("got %v\nwant 1 warning", c.Warnings())
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	s := newExcludeTestSchema()
+	c := &Config{
+		Exclude:     []string{"logs", "migrations", "tmp_*"},
+		LintExclude: []string{"post*"},
+		Relations: []AdditionalRelation{
+			{Table: "logs", Columns: []string{"user_id"}, ParentTable: "users", ParentColumns: []string{"uid"}},
+			{Table: "comments", Columns: []string{"post_id"}, ParentTable: "*s", ParentColumns: []string{"id"}},
+		},
+		Comments: []AdditionalComment{
+			{Table: "users", ColumnComments: map[string]string{"username": "name", "emal": "email"}},
+			{Table: "user", ColumnComments: map[string]string{"id": "id"}},
+			{Table: "*", ColumnComments: map[string]string{"created_*": "created"}},
+		},
+		Viewpoints: []Viewpoint{{Name: "posts", Tables: []string{"posts", "tags"}}},
+	}
+	got := []string{}
+	for _, err := range c.ValidateSchema(s) {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"exclude: table 'migrations' not found",
+		"exclude: pattern 'tmp_*' matches no table",
+		"relations[0].columns: column 'logs.user_id' not found",
+		"relations[0].parentColumns: column 'users.uid' not found",
+		"relations[1].parentTable: pattern '*s' matches 4 tables",
+		"comments[0].columnComments: column 'users.emal' not found",
+		"comments[1].table: table 'user' not found",
+		"comments[2].columnComments: pattern 'created_*' matches no column",
+		"viewpoints[0].tables: table 'tags' not found",
+	}
+	if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	got, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join(testdataDir(), "..", "tbls.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("tbls.schema.json is outdated. run `tbls config schema > tbls.schema.json`")
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	config, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = config.LoadConfigFile(filepath.Join(testdataDir(), "templates_test_tbls.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filepath.Join(testdataDir(), "templates", "table.md.tmpl")
+	if config.Templates.MD.Table != expected {
+		t.Errorf("actual %v\nwant %v", config.Templates.MD.Table, expected)
+	}
+	if config.Templates.MD.Index != "" {
+		t.Errorf("actual %v\nwant %v", config.Templates.MD.Index, "")
+	}
+}
+
+func TestLoadColumnFormat(t *testing.T) {
+	config, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = config.LoadConfigFile(filepath.Join(testdataDir(), "columns_test_tbls.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ColumnFormat{
+		{Name: "Name"},
+		{Name: "Type"},
+		{Name: "Nullable"},
+		{Name: "Comment", Header: "Description"},
+		{Header: "Example", Value: "ex. {{ .Column.Name }}"},
+	}
+	if fmt.Sprintf("%v", config.Format.Columns) != fmt.Sprintf("%v", expected) {
+		t.Errorf("actual %v\nwant %v", config.Format.Columns, expected)
+	}
+	if config.Format.HideEmptyColumns == nil || !*config.Format.HideEmptyColumns {
+		t.Errorf("actual %v\nwant %v", config.Format.HideEmptyColumns, true)
+	}
+	b, err := yaml.Marshal(config.Format.Columns[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "- Name\n- Type\n" {
+		t.Errorf("actual %v\nwant %v", string(b), "- Name\n- Type\n")
+	}
+}
+
 var tests = []struct {
 	value    string
 	expected string
@@ -134,6 +397,145 @@ func TestMergeAditionalData(t *testing.T) {
 	}
 }
 
+func TestMergeLabels(t *testing.T) {
+	s := &schema.Schema{
+		Name: "testschema",
+		Tables: []*schema.Table{
+			&schema.Table{
+				Name:    "a",
+				Comment: "table a @deprecated",
+				Columns: []*schema.Column{
+					&schema.Column{
+						Name:    "a",
+						Comment: "column a @retention:90d",
+					},
+					&schema.Column{
+						Name:    "a2",
+						Comment: "mail@example.com",
+					},
+				},
+			},
+		},
+	}
+	c, err := NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "labels_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	c.Comments[0].TableComment = ""
+	c.Comments[0].ColumnComments = nil
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	tests := []struct {
+		got, want string
+	}{
+		{s.Tables[0].Comment, "table a"},
+		{fmt.Sprintf("%v", s.Tables[0].Labels), "[owner: team-growth deprecated]"},
+		{s.Tables[0].Columns[0].Comment, "column a"},
+		{fmt.Sprintf("%v", s.Tables[0].Columns[0].Labels), "[pii retention: 90d]"},
+		{s.Tables[0].Columns[1].Comment, "mail@example.com"},
+		{fmt.Sprintf("%v", s.Tables[0].Columns[1].Labels), "[]"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %v\nwant %v", tt.got, tt.want)
+		}
+	}
+
+	b, err := yaml.Marshal(c.Comments[0].Labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- owner: team-growth\n"; string(b) != want {
+		t.Errorf("got %q\nwant %q", string(b), want)
+	}
+}
+
+func TestViewpointSchema(t *testing.T) {
+	users := &schema.Table{Name: "users", Columns: []*schema.Column{&schema.Column{Name: "id"}}}
+	payments := &schema.Table{Name: "payments", Columns: []*schema.Column{&schema.Column{Name: "user_id"}}}
+	paymentLogs := &schema.Table{Name: "payment_logs", Columns: []*schema.Column{&schema.Column{Name: "email", Labels: schema.Labels{&schema.Label{Name: "pii"}}}}}
+	r := &schema.Relation{
+		Table:         payments,
+		Columns:       []*schema.Column{payments.Columns[0]},
+		ParentTable:   users,
+		ParentColumns: []*schema.Column{users.Columns[0]},
+	}
+	s := &schema.Schema{
+		Name:      "testschema",
+		Tables:    []*schema.Table{users, payments, paymentLogs},
+		Relations: []*schema.Relation{r},
+	}
+	tests := []struct {
+		viewpoint     Viewpoint
+		wantTables    string
+		wantRelations int
+		wantErr       bool
+	}{
+		{Viewpoint{Name: "users", Tables: []string{"users"}}, "[users]", 0, false},
+		{Viewpoint{Name: "payments", Tables: []string{"users", "payment*"}}, "[users payments payment_logs]", 1, false},
+		{Viewpoint{Name: "pii", Labels: []string{"pii"}}, "[payment_logs]", 0, false},
+		{Viewpoint{Name: "unknown", Tables: []string{"orders"}}, "", 0, true},
+	}
+	for _, tt := range tests {
+		vs, err := tt.viewpoint.Schema(s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got nil\nwant error", tt.viewpoint.Name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, t := range vs.Tables {
+			names = append(names, t.Name)
+		}
+		if got := fmt.Sprintf("%v", names); got != tt.wantTables {
+			t.Errorf("%s: got %v\nwant %v", tt.viewpoint.Name, got, tt.wantTables)
+		}
+		if len(vs.Relations) != tt.wantRelations {
+			t.Errorf("%s: got %v\nwant %v", tt.viewpoint.Name, len(vs.Relations), tt.wantRelations)
+		}
+	}
+	if len(s.Tables) != 3 || len(s.Relations) != 1 {
+		t.Error("original schema is modified")
+	}
+}
+
+func TestViewpointFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"users", "viewpoint-users"},
+		{"Table A", "viewpoint-table-a"},
+		{"payments / logs", "viewpoint-payments-logs"},
+		{"../secret*", "viewpoint-secret"},
+		{"ユーザー", "viewpoint-ユーザー"},
+	}
+	for _, tt := range tests {
+		if got := (Viewpoint{Name: tt.name}).FileName(); got != tt.want {
+			t.Errorf("%s: got %v\nwant %v", tt.name, got, tt.want)
+		}
+	}
+
+	c := &Config{Viewpoints: []Viewpoint{{Name: "Table A"}, {Name: "table-a"}}}
+	if err := c.validateViewpoints(); err == nil {
+		t.Error("got nil\nwant error")
+	}
+	c = &Config{Viewpoints: []Viewpoint{{Name: ""}}}
+	if err := c.validateViewpoints(); err == nil {
+		t.Error("got nil\nwant error")
+	}
+}
+
 func TestExcludeTables(t *testing.T) {
 	s := schema.Schema{
 		Name: "testschema",
@@ -204,6 +606,146 @@ func TestExcludeTables(t *testing.T) {
 	}
 }
 
+func TestExcludeTablesRelations(t *testing.T) {
+	tests := []struct {
+		include          []string
+		exclude          []string
+		excludeRelations string
+		wantTables       []string
+		wantRelations    []string
+		wantErr          bool
+	}{
+		{nil, []string{"users"}, "", nil, nil, true},
+		{nil, []string{"users"}, ExcludeRelationsError, nil, nil, true},
+		{nil, []string{"users"}, "unknown", nil, nil, true},
+		{nil, []string{"users"}, ExcludeRelationsDrop, []string{"posts", "comments", "logs"}, []string{"comments.post_id -> posts.id"}, false},
+		{[]string{"comments"}, nil, ExcludeRelationsDrop, []string{"comments"}, []string{}, false},
+		{[]string{"comments", "logs"}, []string{"logs"}, ExcludeRelationsDrop, []string{"comments"}, []string{}, false},
+		{
+			[]string{"comments"}, nil, ExcludeRelationsExternal,
+			[]string{"users ( external )", "posts ( external )", "comments"},
+			[]string{"comments.post_id -> posts.id", "comments.user_id -> users.id"},
+			false,
+		},
+		{[]string{"users", "posts"}, nil, ExcludeRelationsError, []string{"users", "posts"}, []string{"posts.user_id -> users.id"}, false},
+	}
+	for _, tt := range tests {
+		s := newExcludeTestSchema()
+		c := &Config{Include: tt.include, Exclude: tt.exclude, ExcludeRelations: tt.excludeRelations}
+		err := c.ExcludeTables(s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%v %v %s: got nil\nwant error", tt.include, tt.exclude, tt.excludeRelations)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotTables := []string{}
+		for _, tbl := range s.Tables {
+			if tbl.External {
+				gotTables = append(gotTables, fmt.Sprintf("%s ( external )", tbl.Name))
+				continue
+			}
+			gotTables = append(gotTables, tbl.Name)
+		}
+		if fmt.Sprintf("%v", gotTables) != fmt.Sprintf("%v", tt.wantTables) {
+			t.Errorf("%v %v %s: got %v\nwant %v", tt.include, tt.exclude, tt.excludeRelations, gotTables, tt.wantTables)
+		}
+		gotRelations := []string{}
+		for _, r := range s.Relations {
+			gotRelations = append(gotRelations, fmt.Sprintf("%s.%s -> %s.%s", r.Table.Name, r.Columns[0].Name, r.ParentTable.Name, r.ParentColumns[0].Name))
+		}
+		if fmt.Sprintf("%v", gotRelations) != fmt.Sprintf("%v", tt.wantRelations) {
+			t.Errorf("%v %v %s: got %v\nwant %v", tt.include, tt.exclude, tt.excludeRelations, gotRelations, tt.wantRelations)
+		}
+		for _, tbl := range s.Tables {
+			for _, col := range tbl.Columns {
+				for _, r := range append(col.ParentRelations, col.ChildRelations...) {
+					if !containsRelation(s.Relations, r) {
+						t.Errorf("%v %v %s: %s.%s has removed relation", tt.include, tt.exclude, tt.excludeRelations, tbl.Name, col.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestExcludeTablesExternal(t *testing.T) {
+	s := newExcludeTestSchema()
+	c := &Config{Include: []string{"comments"}, ExcludeRelations: ExcludeRelationsExternal}
+	if err := c.ExcludeTables(s); err != nil {
+		t.Fatal(err)
+	}
+	posts, err := s.FindTableByName("posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(posts.Columns); got != 1 {
+		t.Errorf("got %v\nwant %v", got, 1)
+	}
+	if got := len(posts.Columns[0].ChildRelations); got != 1 {
+		t.Errorf("got %v\nwant %v", got, 1)
+	}
+	if posts.Def != "" || len(posts.Indexes) != 0 {
+		t.Error("external table should be a stub")
+	}
+}
+
+func newExcludeTestSchema() *schema.Schema {
+	users := &schema.Table{
+		Name: "users",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "serial"},
+			&schema.Column{Name: "username", Type: "text"},
+		},
+	}
+	posts := &schema.Table{
+		Name: "posts",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "serial"},
+			&schema.Column{Name: "user_id", Type: "int"},
+			&schema.Column{Name: "title", Type: "text"},
+		},
+		Indexes: []*schema.Index{&schema.Index{Name: "posts_pkey", Def: "PRIMARY KEY (id)"}},
+		Def:     "CREATE TABLE posts ( ... )",
+	}
+	comments := &schema.Table{
+		Name: "comments",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "serial"},
+			&schema.Column{Name: "post_id", Type: "int"},
+			&schema.Column{Name: "user_id", Type: "int"},
+		},
+	}
+	logs := &schema.Table{
+		Name: "logs",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "serial"},
+		},
+	}
+	s := &schema.Schema{
+		Name:   "testschema",
+		Tables: []*schema.Table{users, posts, comments, logs},
+	}
+	relate := func(t *schema.Table, c *schema.Column, pt *schema.Table, pc *schema.Column) {
+		r := &schema.Relation{
+			Table:         t,
+			Columns:       []*schema.Column{c},
+			ParentTable:   pt,
+			ParentColumns: []*schema.Column{pc},
+		}
+		c.ParentRelations = append(c.ParentRelations, r)
+		pc.ChildRelations = append(pc.ChildRelations, r)
+		s.Relations = append(s.Relations, r)
+	}
+	relate(posts, posts.Columns[1], users, users.Columns[0])
+	relate(comments, comments.Columns[1], posts, posts.Columns[0])
+	relate(comments, comments.Columns[2], users, users.Columns[0])
+	return s
+}
+
 func TestModifySchema(t *testing.T) {
 	s := schema.Schema{
 		Name: "testschema",
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Melsoft-Games/tbls/output/typemap"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// Strategies of detectVirtualRelations
+const (
+	// StrategyPlural detects `<singular>_id -> <plural>.id` ( e.g. `user_id -> users.id` )
+	StrategyPlural = "plural"
+	// StrategySingular detects `<name>_id -> <name>.id` ( e.g. `user_id -> user.id` )
+	StrategySingular = "singular"
+)
+
+var strategyRules = map[string]DetectRule{
+	StrategyPlural: DetectRule{
+		Column:       `^(?P<name>.+)_id$`,
+		ParentTable:  `{{ plural .name }}`,
+		ParentColumn: "id",
+	},
+	StrategySingular: DetectRule{
+		Column:       `^(?P<name>.+)_id$`,
+		ParentTable:  `{{ .name }}`,
+		ParentColumn: "id",
+	},
+}
+
+var strategyDefs = map[string]string{
+	StrategyPlural:   "<singular>_id -> <plural>.id",
+	StrategySingular: "<name>_id -> <name>.id",
+}
+
+// DetectVirtualRelations is the setting to detect virtual relations by naming convention
+type DetectVirtualRelations struct {
+	Enabled    bool         `yaml:"enabled"`
+	Strategies []string     `yaml:"strategies,omitempty"`
+	Rules      []DetectRule `yaml:"rules,omitempty"`
+	TypeCheck  *bool        `yaml:"typeCheck,omitempty"`
+}
+
+// DetectRule is the rule to detect virtual relation.
+// Column is the regexp of column name, and ParentTable / ParentColumn are Go text/template
+// rendered with the named groups of Column, `.Table` and `.Column`.
+type DetectRule struct {
+	Column       string `yaml:"column"`
+	ParentTable  string `yaml:"parentTable"`
+	ParentColumn string `yaml:"parentColumn"`
+}
+
+type detector struct {
+	def          string
+	column       *regexp.Regexp
+	parentTable  *template.Template
+	parentColumn *template.Template
+}
+
+var detectFuncs = template.FuncMap{
+	"plural":   plural,
+	"singular": singular,
+}
+
+// detectors return detectors of strategies and rules. Default strategy is `plural`.
+func (d DetectVirtualRelations) detectors() ([]*detector, error) {
+	rules := []DetectRule{}
+	defs := []string{}
+	strategies := d.Strategies
+	if len(strategies) == 0 && len(d.Rules) == 0 {
+		strategies = []string{StrategyPlural}
+	}
+	for _, s := range strategies {
+		r, ok := strategyRules[s]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("failed to detect virtual relations: unsupported strategy '%s'", s))
+		}
+		rules = append(rules, r)
+		defs = append(defs, fmt.Sprintf("Detected by naming convention: %s", strategyDefs[s]))
+	}
+	for _, r := range d.Rules {
+		rules = append(rules, r)
+		defs = append(defs, fmt.Sprintf("Detected by rule: %s -> %s.%s", r.Column, r.ParentTable, r.ParentColumn))
+	}
+
+	detectors := []*detector{}
+	for i, r := range rules {
+		if r.Column == "" || r.ParentTable == "" || r.ParentColumn == "" {
+			return nil, errors.New("failed to detect virtual relations: column, parentTable and parentColumn of rule are required")
+		}
+		column, err := regexp.Compile(r.Column)
+		if err != nil {
+			return nil, errors.Wrap(errors.WithStack(err), "failed to detect virtual relations")
+		}
+		parentTable, err := template.New("parentTable").Funcs(detectFuncs).Parse(r.ParentTable)
+		if err != nil {
+			return nil, errors.Wrap(errors.WithStack(err), "failed to detect virtual relations")
+		}
+		parentColumn, err := template.New("parentColumn").Funcs(detectFuncs).Parse(r.ParentColumn)
+		if err != nil {
+			return nil, errors.Wrap(errors.WithStack(err), "failed to detect virtual relations")
+		}
+		detectors = append(detectors, &detector{
+			def:          defs[i],
+			column:       column,
+			parentTable:  parentTable,
+			parentColumn: parentColumn,
+		})
+	}
+	return detectors, nil
+}
+
+// detectVirtualRelations add virtual relations detected by naming convention to schema.Schema.
+// Columns that already have parent relations and excluded parent tables are skipped.
+func detectVirtualRelations(s *schema.Schema, d DetectVirtualRelations, excluded func(string) bool) error {
+	if !d.Enabled {
+		return nil
+	}
+	detectors, err := d.detectors()
+	if err != nil {
+		return err
+	}
+	typeCheck := d.TypeCheck == nil || *d.TypeCheck
+	driver := ""
+	if s.Driver != nil {
+		driver = s.Driver.Name
+	}
+
+	for _, t := range s.Tables {
+		for _, c := range t.Columns {
+			if len(c.ParentRelations) > 0 {
+				continue
+			}
+			for _, dt := range detectors {
+				pt, pc, err := dt.detect(s, t, c)
+				if err != nil {
+					return err
+				}
+				if pt == nil || excluded(pt.Name) {
+					continue
+				}
+				if pt == t && pc == c {
+					continue
+				}
+				if typeCheck && !compatibleTypes(driver, c.Type, pc.Type) {
+					continue
+				}
+				r := &schema.Relation{
+					Table:         t,
+					Columns:       []*schema.Column{c},
+					ParentTable:   pt,
+					ParentColumns: []*schema.Column{pc},
+					Def:           dt.def,
+					Virtual:       true,
+				}
+				c.ParentRelations = append(c.ParentRelations, r)
+				pc.ChildRelations = append(pc.ChildRelations, r)
+				s.Relations = append(s.Relations, r)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// detect return the parent table and column of the column, or nil if not found.
+// Nested columns ( e.g. BigQuery `a.user_id` ) are matched by the last name.
+// Parent table in the same namespace ( e.g. BigQuery dataset ) is preferred.
+func (dt *detector) detect(s *schema.Schema, t *schema.Table, c *schema.Column) (*schema.Table, *schema.Column, error) {
+	name := c.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	m := dt.column.FindStringSubmatch(name)
+	if m == nil {
+		return nil, nil, nil
+	}
+	data := map[string]interface{}{
+		"Table":  t.Name,
+		"Column": c.Name,
+	}
+	for i, g := range dt.column.SubexpNames() {
+		if g != "" {
+			data[g] = m[i]
+		}
+	}
+	ptName, err := render(dt.parentTable, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	pcName, err := render(dt.parentColumn, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates := []string{ptName}
+	if i := strings.LastIndex(t.Name, "."); i >= 0 && !strings.Contains(ptName, ".") {
+		candidates = []string{fmt.Sprintf("%s.%s", t.Name[:i], ptName), ptName}
+	}
+	for _, n := range candidates {
+		pt, err := s.FindTableByName(n)
+		if err != nil {
+			continue
+		}
+		pc, err := pt.FindColumnByName(pcName)
+		if err != nil {
+			continue
+		}
+		return pt, pc, nil
+	}
+	return nil, nil, nil
+}
+
+func render(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	err := tmpl.Execute(buf, data)
+	if err != nil {
+		return "", errors.Wrap(errors.WithStack(err), "failed to detect virtual relations")
+	}
+	return buf.String(), nil
+}
+
+// compatibleTypes return true if the column types can be related
+func compatibleTypes(driver, a, b string) bool {
+	ta := typemap.Map(driver, a)
+	tb := typemap.Map(driver, b)
+	if ta.Repeated || tb.Repeated {
+		return false
+	}
+	ka := compatibleKind(ta.Kind)
+	kb := compatibleKind(tb.Kind)
+	if ka == typemap.Unknown || kb == typemap.Unknown {
+		return strings.EqualFold(baseType(a), baseType(b))
+	}
+	return ka == kb
+}
+
+func compatibleKind(k typemap.Kind) typemap.Kind {
+	switch k {
+	case typemap.Int32:
+		return typemap.Int64
+	case typemap.UUID, typemap.Enum:
+		return typemap.String
+	}
+	return k
+}
+
+func baseType(typ string) string {
+	if i := strings.Index(typ, "("); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.TrimSpace(typ)
+}
+
+var irregulars = map[string]string{
+	"person": "people",
+	"child":  "children",
+	"mouse":  "mice",
+}
+
+// plural return the plural form of English noun ( e.g. `user` -> `users`, `category` -> `categories` )
+func plural(s string) string {
+	for sg, pl := range irregulars {
+		if strings.HasSuffix(s, sg) {
+			return strings.TrimSuffix(s, sg) + pl
+		}
+	}
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+// singular return the singular form of English noun ( e.g. `users` -> `user`, `categories` -> `category` )
+func singular(s string) string {
+	for sg, pl := range irregulars {
+		if strings.HasSuffix(s, pl) {
+			return strings.TrimSuffix(s, pl) + sg
+		}
+	}
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "zes"),
+		strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"):
+		return s
+	case strings.HasSuffix(s, "s"):
+		return s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestDetectVirtualRelations(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name   string
+		detect DetectVirtualRelations
+		want   []string
+	}{
+		{
+			"disabled",
+			DetectVirtualRelations{},
+			[]string{
+				"posts.category_id -> categories.id ( foreign key )",
+			},
+		},
+		{
+			"default strategy",
+			DetectVirtualRelations{Enabled: true},
+			[]string{
+				"posts.category_id -> categories.id ( foreign key )",
+				"posts.user_id -> users.id ( Detected by naming convention: <singular>_id -> <plural>.id )",
+			},
+		},
+		{
+			"without type check",
+			DetectVirtualRelations{Enabled: true, TypeCheck: &disabled},
+			[]string{
+				"comments.post_id -> posts.id ( Detected by naming convention: <singular>_id -> <plural>.id )",
+				"posts.category_id -> categories.id ( foreign key )",
+				"posts.user_id -> users.id ( Detected by naming convention: <singular>_id -> <plural>.id )",
+			},
+		},
+		{
+			"strategies and rules",
+			DetectVirtualRelations{
+				Enabled:    true,
+				Strategies: []string{StrategyPlural, StrategySingular},
+				Rules: []DetectRule{
+					{Column: `^(author|editor)$`, ParentTable: "users", ParentColumn: "id"},
+				},
+			},
+			[]string{
+				"comments.author -> users.id ( Detected by rule: ^(author|editor)$ -> users.id )",
+				"posts.category_id -> categories.id ( foreign key )",
+				"posts.tag_id -> tag.id ( Detected by naming convention: <name>_id -> <name>.id )",
+				"posts.user_id -> users.id ( Detected by naming convention: <singular>_id -> <plural>.id )",
+			},
+		},
+	}
+	for _, tt := range tests {
+		s := newDetectTestSchema()
+		err := detectVirtualRelations(s, tt.detect, (&Config{}).excluded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, r := range s.Relations {
+			def := r.Def
+			if !r.Virtual {
+				def = "foreign key"
+			}
+			got = append(got, fmt.Sprintf("%s.%s -> %s.%s ( %s )", r.Table.Name, r.Columns[0].Name, r.ParentTable.Name, r.ParentColumns[0].Name, def))
+		}
+		sort.Strings(got)
+		if fmt.Sprintf("%v", got) != fmt.Sprintf("%v", tt.want) {
+			t.Errorf("%s: got %v\nwant %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectVirtualRelationsNamespace(t *testing.T) {
+	users := &schema.Table{Name: "dataset.users", Columns: []*schema.Column{&schema.Column{Name: "id", Type: "INT64"}}}
+	otherUsers := &schema.Table{Name: "users", Columns: []*schema.Column{&schema.Column{Name: "id", Type: "INT64"}}}
+	events := &schema.Table{Name: "dataset.events", Columns: []*schema.Column{
+		&schema.Column{Name: "user", Type: "RECORD"},
+		&schema.Column{Name: "user.user_id", Type: "INT64"},
+	}}
+	s := &schema.Schema{
+		Tables: []*schema.Table{users, otherUsers, events},
+		Driver: &schema.Driver{Name: "bigquery"},
+	}
+	err := detectVirtualRelations(s, DetectVirtualRelations{Enabled: true}, (&Config{}).excluded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Relations) != 1 {
+		t.Fatalf("got %v\nwant 1", len(s.Relations))
+	}
+	if s.Relations[0].ParentTable != users {
+		t.Errorf("got %v\nwant %v", s.Relations[0].ParentTable.Name, users.Name)
+	}
+	if len(events.Columns[1].ParentRelations) != 1 || len(users.Columns[0].ChildRelations) != 1 {
+		t.Error("column relations are not added")
+	}
+
+	s = &schema.Schema{
+		Tables: []*schema.Table{users, events},
+		Driver: &schema.Driver{Name: "bigquery"},
+	}
+	events.Columns[1].ParentRelations = nil
+	err = detectVirtualRelations(s, DetectVirtualRelations{Enabled: true}, (&Config{Exclude: []string{"dataset.users"}}).excluded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Relations) != 0 {
+		t.Errorf("relation to excluded table: got %v\nwant 0", len(s.Relations))
+	}
+}
+
+func TestDetectVirtualRelationsInclude(t *testing.T) {
+	s := newDetectTestSchema()
+	c := &Config{
+		Include:                []string{"posts", "categories"},
+		DetectVirtualRelations: DetectVirtualRelations{Enabled: true},
+	}
+	if err := c.ModifySchema(s); err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, t := range s.Tables {
+		got = append(got, t.Name)
+	}
+	if want := "[categories posts]"; fmt.Sprintf("%v", got) != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+	if len(s.Relations) != 1 || s.Relations[0].Virtual {
+		t.Errorf("got %v\nwant only the foreign key", len(s.Relations))
+	}
+}
+
+func TestDetectVirtualRelationsError(t *testing.T) {
+	tests := []DetectVirtualRelations{
+		{Enabled: true, Strategies: []string{"unknown"}},
+		{Enabled: true, Rules: []DetectRule{{Column: "(", ParentTable: "users", ParentColumn: "id"}}},
+		{Enabled: true, Rules: []DetectRule{{Column: "^author$", ParentTable: "{{ .name", ParentColumn: "id"}}},
+		{Enabled: true, Rules: []DetectRule{{Column: "^author$", ParentTable: "users"}}},
+	}
+	for _, tt := range tests {
+		err := detectVirtualRelations(newDetectTestSchema(), tt, (&Config{}).excluded)
+		if err == nil {
+			t.Errorf("%v: got nil\nwant error", tt)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		singular string
+		plural   string
+	}{
+		{"user", "users"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"branch", "branches"},
+		{"person", "people"},
+		{"sales_person", "sales_people"},
+	}
+	for _, tt := range tests {
+		if got := plural(tt.singular); got != tt.plural {
+			t.Errorf("plural(%s): got %v\nwant %v", tt.singular, got, tt.plural)
+		}
+		if got := singular(tt.plural); got != tt.singular {
+			t.Errorf("singular(%s): got %v\nwant %v", tt.plural, got, tt.singular)
+		}
+	}
+}
+
+func newDetectTestSchema() *schema.Schema {
+	users := &schema.Table{
+		Name: "users",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "bigint(20)"},
+		},
+	}
+	categories := &schema.Table{
+		Name: "categories",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "int(11)"},
+		},
+	}
+	tag := &schema.Table{
+		Name: "tag",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "int(11)"},
+		},
+	}
+	posts := &schema.Table{
+		Name: "posts",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "bigint(20)"},
+			&schema.Column{Name: "user_id", Type: "int(11)"},
+			&schema.Column{Name: "category_id", Type: "int(11)"},
+			&schema.Column{Name: "tag_id", Type: "int(11)"},
+		},
+	}
+	comments := &schema.Table{
+		Name: "comments",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "bigint(20)"},
+			&schema.Column{Name: "post_id", Type: "varchar(255)"},
+			&schema.Column{Name: "author", Type: "bigint(20)"},
+		},
+	}
+	r := &schema.Relation{
+		Table:         posts,
+		Columns:       []*schema.Column{posts.Columns[2]},
+		ParentTable:   categories,
+		ParentColumns: []*schema.Column{categories.Columns[0]},
+		Def:           "FOREIGN KEY (category_id) REFERENCES categories (id)",
+	}
+	posts.Columns[2].ParentRelations = []*schema.Relation{r}
+	categories.Columns[0].ChildRelations = []*schema.Relation{r}
+	return &schema.Schema{
+		Name:      "testschema",
+		Tables:    []*schema.Table{users, categories, tag, posts, comments},
+		Relations: []*schema.Relation{r},
+		Driver:    &schema.Driver{Name: "mysql"},
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Lists that are replaced instead of appended by `extends:`
+var replacedLists = map[string]bool{
+	"dsn":            true,
+	"format.columns": true,
+}
+
+// Paths in config that are relative to the config file
+var relativePaths = [][]string{
+	{"templates", "md", "index"},
+	{"templates", "md", "table"},
+	{"templates", "md", "viewpoint"},
+	{"templates", "dot", "schema"},
+	{"templates", "dot", "table"},
+	{"templates", "puml", "schema"},
+	{"templates", "puml", "table"},
+	{"inferRelations", "queryLogs"},
+}
+
+// loadConfigYAML load the config file and merge it over the files of `extends:`.
+// Maps are merged by key, lists are appended ( `dsn` and `format.columns` are replaced ) and other values are overridden.
+// Unknown keys are collected as warnings of the config unless strict.
+func (c *Config) loadConfigYAML(path string, loading map[string]bool) (yaml.MapSlice, error) {
+	if loading[path] {
+		return nil, errors.New(fmt.Sprintf("circular extends: '%s'", path))
+	}
+	loading[path] = true
+	defer delete(loading, path)
+
+	buf, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	warnings, err := decodeStrict(buf, path, c.strict)
+	c.warnings = append(c.warnings, warnings...)
+	if err != nil {
+		return nil, err
+	}
+	doc := yaml.MapSlice{}
+	err = yaml.Unmarshal(buf, &doc)
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to parse '%s'", path))
+	}
+
+	extends := []string{}
+	merged := yaml.MapSlice{}
+	for _, item := range doc {
+		if item.Key != "extends" {
+			merged = append(merged, item)
+			continue
+		}
+		switch v := item.Value.(type) {
+		case string:
+			extends = append(extends, v)
+		case []interface{}:
+			for _, e := range v {
+				s, ok := e.(string)
+				if !ok {
+					return nil, errors.New(fmt.Sprintf("invalid extends in '%s': %v", path, e))
+				}
+				extends = append(extends, s)
+			}
+		case nil:
+		default:
+			return nil, errors.New(fmt.Sprintf("invalid extends in '%s': %v", path, v))
+		}
+	}
+	doc = merged
+
+	var base interface{} = yaml.MapSlice{}
+	for _, e := range extends {
+		paths, err := expandExtends(e, filepath.Dir(path))
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to extend '%s'", path))
+		}
+		for _, p := range paths {
+			d, err := c.loadConfigYAML(p, loading)
+			if err != nil {
+				return nil, err
+			}
+			err = resolveRelativePaths(d, filepath.Dir(p))
+			if err != nil {
+				return nil, err
+			}
+			base = mergeYAML(base, d, "")
+		}
+	}
+	if len(extends) == 0 {
+		return doc, nil
+	}
+	return mergeYAML(base, doc, "").(yaml.MapSlice), nil
+}
+
+// expandExtends return the paths of `extends:` entry that is a path or a file glob relative to dir
+func expandExtends(e, dir string) ([]string, error) {
+	e, err := parseWithEnviron(e)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !filepath.IsAbs(e) {
+		e = filepath.Join(dir, e)
+	}
+	if !strings.ContainsAny(e, "*?[") {
+		return []string{e}, nil
+	}
+	paths, err := filepath.Glob(e)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return paths, nil
+}
+
+// mergeYAML merge override into base
+func mergeYAML(base, override interface{}, key string) interface{} {
+	switch o := override.(type) {
+	case yaml.MapSlice:
+		b, ok := base.(yaml.MapSlice)
+		if !ok {
+			return o
+		}
+		merged := append(yaml.MapSlice{}, b...)
+		for _, item := range o {
+			k := fmt.Sprintf("%v", item.Key)
+			if key != "" {
+				k = fmt.Sprintf("%s.%s", key, k)
+			}
+			found := false
+			for i := range merged {
+				if merged[i].Key == item.Key {
+					merged[i].Value = mergeYAML(merged[i].Value, item.Value, k)
+					found = true
+					break
+				}
+			}
+			if !found {
+				merged = append(merged, item)
+			}
+		}
+		return merged
+	case []interface{}:
+		b, ok := base.([]interface{})
+		if !ok || replacedLists[key] {
+			return o
+		}
+		return append(append([]interface{}{}, b...), o...)
+	}
+	return override
+}
+
+// resolveRelativePaths make paths in the config ( and its profiles ) of extends absolute, because they are relative to the file
+func resolveRelativePaths(doc yaml.MapSlice, dir string) error {
+	for _, item := range doc {
+		if item.Key != "profiles" {
+			continue
+		}
+		profiles, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		for _, p := range profiles {
+			if pd, ok := p.Value.(yaml.MapSlice); ok {
+				if err := resolveRelativePaths(pd, dir); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, keys := range relativePaths {
+		m := doc
+		for i, k := range keys {
+			idx := -1
+			for j := range m {
+				if m[j].Key == k {
+					idx = j
+					break
+				}
+			}
+			if idx < 0 {
+				break
+			}
+			if i < len(keys)-1 {
+				next, ok := m[idx].Value.(yaml.MapSlice)
+				if !ok {
+					break
+				}
+				m = next
+				continue
+			}
+			switch v := m[idx].Value.(type) {
+			case string:
+				p, err := resolvePath(v, dir)
+				if err != nil {
+					return err
+				}
+				m[idx].Value = p
+			case []interface{}:
+				for j, e := range v {
+					s, ok := e.(string)
+					if !ok {
+						continue
+					}
+					p, err := resolvePath(s, dir)
+					if err != nil {
+						return err
+					}
+					v[j] = p
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func resolvePath(p, dir string) (string, error) {
+	if p == "" {
+		return p, nil
+	}
+	p, err := parseWithEnviron(p)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	return p, nil
+}
@@ -0,0 +1,388 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// InferRelations is the setting to infer virtual relations from JOIN conditions of view definitions and query logs
+type InferRelations struct {
+	Views     bool     `yaml:"views,omitempty"`
+	QueryLogs []string `yaml:"queryLogs,omitempty"`
+	MinCount  int      `yaml:"minCount,omitempty"`
+}
+
+// query is SQL query with the number of executions
+type query struct {
+	SQL   string
+	Count int
+}
+
+var (
+	sqlCommentRe = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+	spaceRe      = regexp.MustCompile(`\s+`)
+	tableRefRe   = regexp.MustCompile("(?i)\\b(?:FROM|JOIN)\\s+([`\"\\w.\\-]+)")
+	aliasRe      = regexp.MustCompile(`^\s+(?i:AS\s+)?([A-Za-z_]\w*)`)
+	joinCondRe   = regexp.MustCompile("((?:[`\"\\w\\-]+\\.)+)([`\"\\w]+)\\s*=\\s*((?:[`\"\\w\\-]+\\.)+)([`\"\\w]+)")
+	usingRe      = regexp.MustCompile("(?i)\\bJOIN\\s+([`\"\\w.\\-]+)(?:\\s+(?:AS\\s+)?([A-Za-z_]\\w*))?\\s+USING\\s*\\(([^)]*)\\)")
+)
+
+var notAlias = map[string]bool{
+	"ON": true, "USING": true, "WHERE": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true,
+	"FULL": true, "OUTER": true, "CROSS": true, "NATURAL": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "UNION": true, "WINDOW": true, "SET": true, "VALUES": true, "SELECT": true, "LATERAL": true,
+	"UNNEST": true, "WITH": true, "FOR": true, "QUALIFY": true, "OFFSET": true, "EXCEPT": true, "INTERSECT": true,
+}
+
+// inferRelations add virtual relations inferred from JOIN conditions of view definitions and query logs.
+// Relation.Count is the number of views and queries that use the JOIN condition.
+func inferRelations(s *schema.Schema, ir InferRelations, excluded func(string) bool) error {
+	queries := []query{}
+	if ir.Views {
+		for _, t := range s.Tables {
+			if t.Def != "" && strings.Contains(strings.ToUpper(t.Type), "VIEW") {
+				queries = append(queries, query{SQL: t.Def, Count: 1})
+			}
+		}
+	}
+	for _, p := range ir.QueryLogs {
+		qs, err := loadQueryLog(p)
+		if err != nil {
+			return err
+		}
+		queries = append(queries, qs...)
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	type key struct {
+		c, pc *schema.Column
+	}
+	counts := map[key]int{}
+	found := map[key]*schema.Relation{}
+	order := []key{}
+	for _, q := range queries {
+		used := map[key]bool{}
+		for _, j := range extractJoins(s, q.SQL) {
+			if excluded(j.Table.Name) || excluded(j.ParentTable.Name) {
+				continue
+			}
+			k := key{j.Columns[0], j.ParentColumns[0]}
+			if used[k] {
+				continue
+			}
+			used[k] = true
+			if _, ok := found[k]; !ok {
+				found[k] = j
+				order = append(order, k)
+			}
+			counts[k] += q.Count
+		}
+	}
+
+	minCount := ir.MinCount
+	if minCount < 1 {
+		minCount = 1
+	}
+	for _, k := range order {
+		if counts[k] < minCount || related(k.c, k.pc) {
+			continue
+		}
+		r := found[k]
+		r.Count = counts[k]
+		r.Def = fmt.Sprintf("Inferred from JOIN ( used %d times )", r.Count)
+		r.Virtual = true
+		k.c.ParentRelations = append(k.c.ParentRelations, r)
+		k.pc.ChildRelations = append(k.pc.ChildRelations, r)
+		s.Relations = append(s.Relations, r)
+	}
+	return nil
+}
+
+// related return true if the columns are already related in either direction
+func related(a, b *schema.Column) bool {
+	for _, r := range a.ParentRelations {
+		for _, pc := range r.ParentColumns {
+			if pc == b {
+				return true
+			}
+		}
+	}
+	for _, r := range b.ParentRelations {
+		for _, pc := range r.ParentColumns {
+			if pc == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type tableRef struct {
+	table *schema.Table
+	pos   int
+}
+
+// extractJoins return relations of JOIN conditions ( `a.x = b.y` and `JOIN b USING (x)` ) in the query
+func extractJoins(s *schema.Schema, sql string) []*schema.Relation {
+	q := spaceRe.ReplaceAllString(sqlCommentRe.ReplaceAllString(sql, " "), " ")
+
+	refs := map[string]*tableRef{}
+	ordered := []*tableRef{}
+	for _, m := range tableRefRe.FindAllStringSubmatchIndex(q, -1) {
+		name := q[m[2]:m[3]]
+		t := resolveTable(s, name)
+		if t == nil {
+			continue
+		}
+		ref := &tableRef{table: t, pos: m[0]}
+		ordered = append(ordered, ref)
+		refs[strings.ToLower(unquote(name))] = ref
+		parts := strings.Split(unquote(name), ".")
+		refs[strings.ToLower(parts[len(parts)-1])] = ref
+		if a := aliasRe.FindStringSubmatch(q[m[1]:]); a != nil && !notAlias[strings.ToUpper(a[1])] {
+			refs[strings.ToLower(a[1])] = ref
+		}
+	}
+
+	relations := []*schema.Relation{}
+	for _, m := range joinCondRe.FindAllStringSubmatch(q, -1) {
+		left, ok := refs[strings.ToLower(unquote(strings.TrimSuffix(m[1], ".")))]
+		if !ok {
+			continue
+		}
+		right, ok := refs[strings.ToLower(unquote(strings.TrimSuffix(m[3], ".")))]
+		if !ok {
+			continue
+		}
+		if r := newJoinRelation(left, unquote(m[2]), right, unquote(m[4])); r != nil {
+			relations = append(relations, r)
+		}
+	}
+	for _, m := range usingRe.FindAllStringSubmatchIndex(q, -1) {
+		right := resolveTable(s, q[m[2]:m[3]])
+		if right == nil {
+			continue
+		}
+		// USING relates the joined table and the preceding table
+		var left *tableRef
+		for _, ref := range ordered {
+			if ref.pos < m[0] && ref.table != right {
+				left = ref
+			}
+		}
+		if left == nil {
+			continue
+		}
+		for _, c := range strings.Split(q[m[6]:m[7]], ",") {
+			c = unquote(strings.TrimSpace(c))
+			if r := newJoinRelation(left, c, &tableRef{table: right, pos: m[0]}, c); r != nil {
+				relations = append(relations, r)
+			}
+		}
+	}
+	return relations
+}
+
+// newJoinRelation return the relation of the JOIN condition.
+// The parent is the side of primary key / unique column ( or `id` ), otherwise the table joined later.
+func newJoinRelation(a *tableRef, ac string, b *tableRef, bc string) *schema.Relation {
+	ca, err := a.table.FindColumnByName(ac)
+	if err != nil {
+		return nil
+	}
+	cb, err := b.table.FindColumnByName(bc)
+	if err != nil {
+		return nil
+	}
+	if ca == cb {
+		return nil
+	}
+	child, c, parent, pc := a.table, ca, b.table, cb
+	sa, sb := parentScore(a.table, ca), parentScore(b.table, cb)
+	if sa > sb || (sa == sb && a.pos > b.pos) {
+		child, c, parent, pc = b.table, cb, a.table, ca
+	}
+	return &schema.Relation{
+		Table:         child,
+		Columns:       []*schema.Column{c},
+		ParentTable:   parent,
+		ParentColumns: []*schema.Column{pc},
+		Virtual:       true,
+	}
+}
+
+func parentScore(t *schema.Table, c *schema.Column) int {
+	for _, i := range t.Indexes {
+		def := strings.ToUpper(i.Def)
+		if len(i.Columns) == 1 && i.Columns[0] == c.Name && (strings.Contains(def, "PRIMARY") || strings.Contains(def, "UNIQUE")) {
+			return 2
+		}
+	}
+	for _, cs := range t.Constraints {
+		if (cs.Type == "PRIMARY KEY" || cs.Type == "UNIQUE") && len(cs.Columns) == 1 && cs.Columns[0] == c.Name {
+			return 2
+		}
+	}
+	if strings.EqualFold(c.Name, "id") {
+		return 1
+	}
+	return 0
+}
+
+// resolveTable find table by the name in query.
+// Qualifiers that are not in table names ( e.g. BigQuery project, PostgreSQL `public` ) are ignored.
+func resolveTable(s *schema.Schema, name string) *schema.Table {
+	parts := strings.Split(unquote(name), ".")
+	for i := range parts {
+		n := strings.Join(parts[i:], ".")
+		for _, t := range s.Tables {
+			if strings.EqualFold(t.Name, n) {
+				return t
+			}
+		}
+	}
+	// unqualified name of namespaced table ( e.g. `users` of `dataset.users` )
+	var found *schema.Table
+	for _, t := range s.Tables {
+		if strings.HasSuffix(strings.ToLower(t.Name), "."+strings.ToLower(parts[len(parts)-1])) {
+			if found != nil {
+				return nil
+			}
+			found = t
+		}
+	}
+	return found
+}
+
+func unquote(s string) string {
+	return strings.NewReplacer("`", "", "\"", "").Replace(s)
+}
+
+// loadQueryLog load queries from query log file.
+// CSV ( e.g. pg_stat_statements dump, BigQuery INFORMATION_SCHEMA.JOBS export ) and JSON ( array or newline delimited ) have `query` field, and optional `calls` field as the number of executions.
+// Other files are SQL statements separated by `;`.
+func loadQueryLog(path string) ([]query, error) {
+	b, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "failed to load query log")
+	}
+	var queries []query
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		queries, err = loadQueryLogCSV(b)
+	case ".json", ".jsonl", ".ndjson":
+		queries, err = loadQueryLogJSON(b)
+	default:
+		for _, q := range strings.Split(string(b), ";") {
+			if strings.TrimSpace(q) != "" {
+				queries = append(queries, query{SQL: q, Count: 1})
+			}
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to load query log '%s'", path))
+	}
+	return queries, nil
+}
+
+func loadQueryLogCSV(b []byte) ([]query, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qi, ci := -1, -1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "query":
+			qi = i
+		case "calls":
+			ci = i
+		}
+	}
+	if qi < 0 {
+		return nil, errors.New("`query` column not found")
+	}
+	queries := []query{}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if qi >= len(rec) {
+			continue
+		}
+		q := query{SQL: rec[qi], Count: 1}
+		if ci >= 0 && ci < len(rec) {
+			if n, err := strconv.Atoi(strings.TrimSpace(rec[ci])); err == nil {
+				q.Count = n
+			}
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+type queryLogRecord struct {
+	Query string      `json:"query"`
+	Calls json.Number `json:"calls"`
+}
+
+func (r queryLogRecord) toQuery() query {
+	q := query{SQL: r.Query, Count: 1}
+	if n, err := r.Calls.Int64(); err == nil {
+		q.Count = int(n)
+	}
+	return q
+}
+
+func loadQueryLogJSON(b []byte) ([]query, error) {
+	queries := []query{}
+	if strings.HasPrefix(strings.TrimSpace(string(b)), "[") {
+		records := []queryLogRecord{}
+		err := json.Unmarshal(b, &records)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, r := range records {
+			queries = append(queries, r.toQuery())
+		}
+		return queries, nil
+	}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		r := queryLogRecord{}
+		err := json.Unmarshal([]byte(line), &r)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		queries = append(queries, r.toQuery())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return queries, nil
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestExtractJoins(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{
+			"SELECT * FROM posts p JOIN users u ON p.user_id = u.id",
+			[]string{"posts.user_id -> users.id"},
+		},
+		{
+			"select * from users as u left outer join posts as p on u.id = p.user_id and p.deleted = false",
+			[]string{"posts.user_id -> users.id"},
+		},
+		{
+			"SELECT * FROM comments JOIN posts ON comments.post_id = posts.id /* posts */ JOIN users ON posts.user_id = users.id",
+			[]string{"comments.post_id -> posts.id", "posts.user_id -> users.id"},
+		},
+		{
+			"SELECT * FROM `project.dataset.comments` c JOIN `project.dataset.users` u ON c.user_id = u.id",
+			[]string{"comments.user_id -> users.id"},
+		},
+		{
+			"SELECT * FROM public.comments c JOIN public.profiles USING (user_id)",
+			[]string{"comments.user_id -> profiles.user_id"},
+		},
+		{
+			"SELECT * FROM users u JOIN posts p ON p.title = u.username",
+			[]string{"posts.title -> users.username"},
+		},
+		{
+			"SELECT * FROM users u JOIN users m ON u.id = m.id",
+			[]string{},
+		},
+		{
+			"SELECT * FROM posts p JOIN unknown x ON p.user_id = x.id",
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		for _, r := range extractJoins(newInferTestSchema(), tt.sql) {
+			got = append(got, fmt.Sprintf("%s.%s -> %s.%s", r.Table.Name, r.Columns[0].Name, r.ParentTable.Name, r.ParentColumns[0].Name))
+		}
+		sort.Strings(got)
+		if fmt.Sprintf("%v", got) != fmt.Sprintf("%v", tt.want) {
+			t.Errorf("%s\ngot %v\nwant %v", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestInferRelations(t *testing.T) {
+	tests := []struct {
+		name  string
+		infer InferRelations
+		want  []string
+	}{
+		{
+			"views",
+			InferRelations{Views: true},
+			[]string{
+				"comments.post_id -> posts.id ( 1 )",
+				"posts.user_id -> users.id ( 1 )",
+			},
+		},
+		{
+			"query logs",
+			InferRelations{QueryLogs: []string{
+				filepath.Join(testdataDir(), "query_log_test.csv"),
+				filepath.Join(testdataDir(), "query_log_test.jsonl"),
+				filepath.Join(testdataDir(), "query_log_test.sql"),
+			}},
+			[]string{
+				"comments.post_id -> posts.id ( 30 )",
+				"comments.user_id -> profiles.user_id ( 1 )",
+				"comments.user_id -> users.id ( 2 )",
+				"posts.user_id -> users.id ( 121 )",
+			},
+		},
+		{
+			"minCount",
+			InferRelations{Views: true, MinCount: 10, QueryLogs: []string{
+				filepath.Join(testdataDir(), "query_log_test.csv"),
+				filepath.Join(testdataDir(), "query_log_test.jsonl"),
+			}},
+			[]string{
+				"comments.post_id -> posts.id ( 31 )",
+				"posts.user_id -> users.id ( 121 )",
+			},
+		},
+	}
+	for _, tt := range tests {
+		s := newInferTestSchema()
+		err := inferRelations(s, tt.infer, (&Config{}).excluded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, r := range s.Relations {
+			if !r.Virtual {
+				continue
+			}
+			if want := fmt.Sprintf("Inferred from JOIN ( used %d times )", r.Count); r.Def != want {
+				t.Errorf("got %v\nwant %v", r.Def, want)
+			}
+			if !containsRelation(r.Columns[0].ParentRelations, r) || !containsRelation(r.ParentColumns[0].ChildRelations, r) {
+				t.Errorf("%s: relations of %s.%s are not added", tt.name, r.Table.Name, r.Columns[0].Name)
+			}
+			got = append(got, fmt.Sprintf("%s.%s -> %s.%s ( %d )", r.Table.Name, r.Columns[0].Name, r.ParentTable.Name, r.ParentColumns[0].Name, r.Count))
+		}
+		sort.Strings(got)
+		if fmt.Sprintf("%v", got) != fmt.Sprintf("%v", tt.want) {
+			t.Errorf("%s: got %v\nwant %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadQueryLogError(t *testing.T) {
+	_, err := loadQueryLog(filepath.Join(testdataDir(), "notfound.csv"))
+	if err == nil {
+		t.Error("got nil\nwant error")
+	}
+	_, err = loadQueryLog(filepath.Join(testdataDir(), "config_test_tbls.yml"))
+	if err != nil {
+		t.Errorf("SQL file: %v", err)
+	}
+}
+
+func containsRelation(rs []*schema.Relation, e *schema.Relation) bool {
+	for _, r := range rs {
+		if r == e {
+			return true
+		}
+	}
+	return false
+}
+
+func newInferTestSchema() *schema.Schema {
+	users := &schema.Table{
+		Name: "users",
+		Type: "TABLE",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id"},
+			&schema.Column{Name: "username"},
+		},
+		Indexes: []*schema.Index{
+			&schema.Index{Name: "users_username_key", Def: "CREATE UNIQUE INDEX users_username_key ON users (username)", Columns: []string{"username"}},
+		},
+	}
+	profiles := &schema.Table{
+		Name: "profiles",
+		Type: "TABLE",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "user_id"},
+		},
+		Indexes: []*schema.Index{
+			&schema.Index{Name: "PRIMARY", Def: "PRIMARY KEY (user_id)", Columns: []string{"user_id"}},
+		},
+	}
+	posts := &schema.Table{
+		Name: "posts",
+		Type: "TABLE",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id"},
+			&schema.Column{Name: "user_id"},
+			&schema.Column{Name: "title"},
+		},
+	}
+	comments := &schema.Table{
+		Name: "comments",
+		Type: "TABLE",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id"},
+			&schema.Column{Name: "post_id"},
+			&schema.Column{Name: "user_id"},
+			&schema.Column{Name: "comment"},
+		},
+	}
+	postComments := &schema.Table{
+		Name: "post_comments",
+		Type: "VIEW",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "title"},
+			&schema.Column{Name: "comment"},
+		},
+		Def: `CREATE VIEW post_comments AS (
+  SELECT p.title, c.comment
+  FROM posts AS p
+  LEFT JOIN comments AS c ON c.post_id = p.id
+  LEFT JOIN users AS u ON u.id = p.user_id
+)`,
+	}
+	return &schema.Schema{
+		Name:   "testschema",
+		Tables: []*schema.Table{users, profiles, posts, comments, postComments},
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchemaEnums is the allowed values of config keys
+var jsonSchemaEnums = map[string][]string{
+	"Config.excludeRelations":             {ExcludeRelationsError, ExcludeRelationsDrop, ExcludeRelationsExternal},
+	"DetectVirtualRelations.strategies[]": {StrategyPlural, StrategySingular},
+}
+
+// JSONSchema return JSON Schema of config file ( .tbls.yml ) generated from Config
+func JSONSchema() ([]byte, error) {
+	g := &jsonSchemaGenerator{definitions: map[string]interface{}{}}
+	root, ok := g.generate(reflect.TypeOf(Config{}), "").(map[string]interface{})
+	if !ok {
+		return nil, errors.New("failed to generate JSON Schema")
+	}
+	config := g.definitions["Config"].(map[string]interface{})
+	properties := map[string]interface{}{}
+	for k, v := range config["properties"].(map[string]interface{}) {
+		properties[k] = v
+	}
+	properties["extends"] = map[string]interface{}{
+		"description": "paths or file globs of config files to extend",
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+	properties["profiles"] = map[string]interface{}{
+		"description":          "config merged over the base config when the profile is selected",
+		"type":                 "object",
+		"additionalProperties": root,
+	}
+	doc := map[string]interface{}{
+		"$schema":              jsonSchemaDraft,
+		"title":                "tbls config",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+		"definitions":          g.definitions,
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append(b, '\n'), nil
+}
+
+type jsonSchemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+func (g *jsonSchemaGenerator) generate(t reflect.Type, key string) interface{} {
+	switch t {
+	case reflect.TypeOf(ColumnFormat{}):
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string", "description": "built-in column"},
+				g.structSchema(t),
+			},
+		}
+	case reflect.TypeOf(AdditionalLabel{}):
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string", "description": "label name"},
+				map[string]interface{}{
+					"type":                 "object",
+					"description":          "label name and value",
+					"additionalProperties": map[string]interface{}{"type": []string{"string", "number", "boolean"}},
+					"minProperties":        1,
+					"maxProperties":        1,
+				},
+			},
+		}
+	}
+
+	var s map[string]interface{}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.generate(t.Elem(), key)
+	case reflect.Bool:
+		s = map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		s = map[string]interface{}{"type": "number"}
+	case reflect.String:
+		s = map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		s = map[string]interface{}{"type": "array", "items": g.generate(t.Elem(), key+"[]")}
+	case reflect.Map:
+		s = map[string]interface{}{"type": "object", "additionalProperties": g.generate(t.Elem(), key)}
+	case reflect.Struct:
+		if _, ok := g.definitions[t.Name()]; !ok {
+			g.definitions[t.Name()] = nil
+			g.definitions[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	default:
+		s = map[string]interface{}{}
+	}
+	if enum, ok := jsonSchemaEnums[key]; ok {
+		s["enum"] = enum
+	}
+	return s
+}
+
+func (g *jsonSchemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	g.fields(t, properties)
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (g *jsonSchemaGenerator) fields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		if contains(opts[1:], "inline") {
+			g.fields(f.Type, properties)
+			continue
+		}
+		name := opts[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		properties[name] = g.generate(f.Type, t.Name()+"."+name)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"sort"
 
 	"github.com/Melsoft-Games/tbls/schema"
-	"github.com/minio/minio/pkg/wildcard"
 )
 
 // Lint is the struct for lint config
@@ -195,7 +194,7 @@ func (r ColumnCount) Check(s *schema.Schema, exclude []string) []RuleWarn {
 
 func contains(s []string, e string) bool {
 	for _, v := range s {
-		if wildcard.MatchSimple(v, e) {
+		if matchName(v, e) {
 			return true
 		}
 	}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/minio/minio/pkg/wildcard"
+	"github.com/pkg/errors"
+)
+
+var (
+	regexpCache   = map[string]*regexp.Regexp{}
+	regexpCacheMu sync.Mutex
+)
+
+// isPattern return true if the name in config is a pattern.
+// Pattern is glob ( `*` and `?` ) or regexp enclosed in slashes ( e.g. `/^events_\d{8}$/` ).
+func isPattern(pattern string) bool {
+	return isRegexp(pattern) || strings.ContainsAny(pattern, "*?")
+}
+
+func isRegexp(pattern string) bool {
+	return len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+// matchName return true if the name matches the name, glob or regexp in config
+func matchName(pattern, name string) bool {
+	if !isRegexp(pattern) {
+		return wildcard.Match(pattern, name)
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	regexpCacheMu.Lock()
+	defer regexpCacheMu.Unlock()
+	if re, ok := regexpCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("invalid pattern '%s'", pattern))
+	}
+	regexpCache[pattern] = re
+	return re, nil
+}
+
+// findTables return tables that match the name or pattern.
+// Table name that is not a pattern should exist.
+func findTables(s *schema.Schema, pattern string) ([]*schema.Table, error) {
+	if !isPattern(pattern) {
+		t, err := s.FindTableByName(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return []*schema.Table{t}, nil
+	}
+	tables := []*schema.Table{}
+	for _, t := range s.Tables {
+		if matchName(pattern, t.Name) {
+			tables = append(tables, t)
+		}
+	}
+	return tables, nil
+}
+
+// findColumns return columns that match the name or pattern.
+// Column name that is not a pattern should exist if strict.
+func findColumns(t *schema.Table, pattern string, strict bool) ([]*schema.Column, error) {
+	if !isPattern(pattern) {
+		c, err := t.FindColumnByName(pattern)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+			return []*schema.Column{}, nil
+		}
+		return []*schema.Column{c}, nil
+	}
+	columns := []*schema.Column{}
+	for _, c := range t.Columns {
+		if matchName(pattern, c.Name) {
+			columns = append(columns, c)
+		}
+	}
+	return columns, nil
+}
+
+// patternsFirst sort names so that patterns come before names, and names win over patterns
+func patternsFirst(names []string) []string {
+	sort.SliceStable(names, func(i, j int) bool {
+		pi, pj := isPattern(names[i]), isPattern(names[j])
+		if pi != pj {
+			return pi
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// validatePatterns check regexps of table/column names in config
+func (c *Config) validatePatterns() error {
+	patterns := []string{}
+	patterns = append(patterns, c.Exclude...)
+	patterns = append(patterns, c.Include...)
+	patterns = append(patterns, c.LintExclude...)
+	patterns = append(patterns, c.Lint.RequireTableComment.Exclude...)
+	patterns = append(patterns, c.Lint.RequireColumnComment.Exclude...)
+	patterns = append(patterns, c.Lint.RequireColumnComment.ExcludedTables...)
+	patterns = append(patterns, c.Lint.UnrelatedTable.Exclude...)
+	patterns = append(patterns, c.Lint.ColumnCount.Exclude...)
+	patterns = append(patterns, c.Lint.RequireForeignKeyIndex.Exclude...)
+	for _, rc := range c.Lint.RequireColumns.Columns {
+		patterns = append(patterns, rc.Exclude...)
+	}
+	for _, r := range c.Relations {
+		patterns = append(patterns, r.Table, r.ParentTable)
+	}
+	for _, ac := range c.Comments {
+		patterns = append(patterns, ac.Table)
+		for k := range ac.ColumnComments {
+			patterns = append(patterns, k)
+		}
+		for k := range ac.ColumnLabels {
+			patterns = append(patterns, k)
+		}
+	}
+	for _, v := range c.Viewpoints {
+		patterns = append(patterns, v.Tables...)
+	}
+	for _, p := range patterns {
+		if !isRegexp(p) {
+			continue
+		}
+		if _, err := compilePattern(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
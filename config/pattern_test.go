@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"users", "users", true},
+		{"users", "user", false},
+		{"events_*", "events_20200101", true},
+		{"events_*", "users", false},
+		{"*.events_2020????", "dataset.events_20200101", true},
+		{"tmp?x", "tmp", false},
+		{"tmp?", "tmp", false},
+		{"tmp?", "tmp1", true},
+		{"?", "", false},
+		{"/^events_\\d{8}$/", "events_20200101", true},
+		{"/^events_\\d{8}$/", "events_intraday_20200101", false},
+		{"/(/", "(", false},
+	}
+	for _, tt := range tests {
+		if got := matchName(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchName(%s, %s): got %v\nwant %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExcludeTablesPattern(t *testing.T) {
+	tests := []struct {
+		exclude []string
+		want    []string
+		wantErr bool
+	}{
+		{[]string{"events_*"}, []string{"users", "posts"}, false},
+		{[]string{"/^events_\\d{8}$/"}, []string{"users", "posts", "events_intraday_20200103"}, false},
+		{[]string{"users"}, nil, true},
+		{[]string{"*s"}, []string{"events_20200101", "events_20200102", "events_intraday_20200103"}, false},
+	}
+	for _, tt := range tests {
+		s := newPatternTestSchema()
+		c := &Config{Exclude: tt.exclude}
+		err := c.ExcludeTables(s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%v: got nil\nwant error", tt.exclude)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, tbl := range s.Tables {
+			got = append(got, tbl.Name)
+		}
+		if fmt.Sprintf("%v", got) != fmt.Sprintf("%v", tt.want) {
+			t.Errorf("%v: got %v\nwant %v", tt.exclude, got, tt.want)
+		}
+		for _, r := range s.Relations {
+			if contains(tt.exclude, r.Table.Name) || contains(tt.exclude, r.ParentTable.Name) {
+				t.Errorf("%v: relation to excluded table remains", tt.exclude)
+			}
+		}
+	}
+}
+
+func TestMergeAdditionalDataPattern(t *testing.T) {
+	s := newPatternTestSchema()
+	c := &Config{
+		Relations: []AdditionalRelation{
+			{Table: "events_*", Columns: []string{"user_id"}, ParentTable: "users", ParentColumns: []string{"id"}},
+		},
+		Comments: []AdditionalComment{
+			{
+				Table:        "/^events_\\d{8}$/",
+				TableComment: "daily events",
+				ColumnComments: map[string]string{
+					"*_id":    "id of the row",
+					"user_id": "user of the event",
+				},
+			},
+		},
+	}
+	err := c.MergeAdditionalData(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tbl := range s.Tables {
+		wantComment := ""
+		if tbl.Name == "events_20200101" || tbl.Name == "events_20200102" {
+			wantComment = "daily events"
+			if got := tbl.Columns[0].Comment; got != "id of the row" {
+				t.Errorf("%s.event_id: got %v\nwant %v", tbl.Name, got, "id of the row")
+			}
+			if got := tbl.Columns[1].Comment; got != "user of the event" {
+				t.Errorf("%s.user_id: got %v\nwant %v", tbl.Name, got, "user of the event")
+			}
+		}
+		if tbl.Name == "posts" || tbl.Name == "users" {
+			continue
+		}
+		if tbl.Comment != wantComment {
+			t.Errorf("%s: got %v\nwant %v", tbl.Name, tbl.Comment, wantComment)
+		}
+	}
+	if got := len(s.Relations); got != 4 {
+		t.Errorf("got %v\nwant %v", got, 4)
+	}
+
+	// literal table name should exist
+	c = &Config{Comments: []AdditionalComment{{Table: "events", TableComment: "events"}}}
+	if err := c.MergeAdditionalData(newPatternTestSchema()); err == nil {
+		t.Error("got nil\nwant error")
+	}
+
+	// parentTable pattern should match only one table
+	c = &Config{Relations: []AdditionalRelation{
+		{Table: "posts", Columns: []string{"user_id"}, ParentTable: "events_*", ParentColumns: []string{"event_id"}},
+	}}
+	if err := c.MergeAdditionalData(newPatternTestSchema()); err == nil {
+		t.Error("got nil\nwant error")
+	}
+}
+
+func TestLoadConfigFileInvalidPattern(t *testing.T) {
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "pattern_test_tbls.yml"))
+	if err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("got %v\nwant invalid pattern error", err)
+	}
+}
+
+func newPatternTestSchema() *schema.Schema {
+	users := &schema.Table{
+		Name: "users",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "INT64"},
+		},
+	}
+	posts := &schema.Table{
+		Name: "posts",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "INT64"},
+			&schema.Column{Name: "user_id", Type: "INT64"},
+		},
+	}
+	tables := []*schema.Table{users, posts}
+	for _, n := range []string{"events_20200101", "events_20200102", "events_intraday_20200103"} {
+		tables = append(tables, &schema.Table{
+			Name: n,
+			Columns: []*schema.Column{
+				&schema.Column{Name: "event_id", Type: "STRING"},
+				&schema.Column{Name: "user_id", Type: "INT64"},
+			},
+		})
+	}
+	r := &schema.Relation{
+		Table:         posts,
+		Columns:       []*schema.Column{posts.Columns[1]},
+		ParentTable:   users,
+		ParentColumns: []*schema.Column{users.Columns[0]},
+	}
+	posts.Columns[1].ParentRelations = []*schema.Relation{r}
+	users.Columns[0].ChildRelations = []*schema.Relation{r}
+	return &schema.Schema{
+		Name:      "testschema",
+		Tables:    tables,
+		Relations: []*schema.Relation{r},
+	}
+}
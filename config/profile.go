@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Profile return Option select the profile in config file.
+// Profile is selected before loading config file, and overrides TBLS_PROFILE.
+func Profile(name string) Option {
+	return func(c *Config) error {
+		c.profile = name
+		return nil
+	}
+}
+
+// applyProfile merge the profile of `profiles:` over the config, and remove `profiles:`
+func applyProfile(doc yaml.MapSlice, name string) (yaml.MapSlice, error) {
+	merged := yaml.MapSlice{}
+	profiles := yaml.MapSlice{}
+	for _, item := range doc {
+		if item.Key != "profiles" {
+			merged = append(merged, item)
+			continue
+		}
+		switch v := item.Value.(type) {
+		case yaml.MapSlice:
+			profiles = v
+		case nil:
+		default:
+			return nil, errors.New("invalid profiles: should be a map of profile name to config")
+		}
+	}
+	if name == "" {
+		return merged, nil
+	}
+	names := []string{}
+	for _, p := range profiles {
+		if p.Key != name {
+			names = append(names, fmt.Sprintf("%v", p.Key))
+			continue
+		}
+		switch v := p.Value.(type) {
+		case yaml.MapSlice:
+			return mergeYAML(merged, v, "").(yaml.MapSlice), nil
+		case nil:
+			return merged, nil
+		default:
+			return nil, errors.New(fmt.Sprintf("invalid profile '%s': should be a config", name))
+		}
+	}
+	sort.Strings(names)
+	return nil, errors.New(fmt.Sprintf("profile '%s' is not defined ( defined: [%s] )", name, strings.Join(names, ", ")))
+}
+
+// selectedProfile return the profile selected by Option or TBLS_PROFILE
+func (c *Config) selectedProfile() string {
+	if c.profile != "" {
+		return c.profile
+	}
+	return os.Getenv("TBLS_PROFILE")
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultPasswordEnv is the environment variable that `tbls init` refers to instead of the password in DSN
+const DefaultPasswordEnv = "TBLS_DB_PASSWORD"
+
+const (
+	scaffoldTableComment  = "table comment required."
+	scaffoldColumnComment = "column comment required."
+)
+
+var (
+	tempTableRe  = regexp.MustCompile(`(?i)(^(tmp|temp|bak|backup)_|_(tmp|temp|bak|backup)$)`)
+	shardTableRe = regexp.MustCompile(`^(.*[^\d_])(_?)(\d{4}_?\d{2}(_?\d{2})?)$`)
+	digitsRe     = regexp.MustCompile(`\d+`)
+)
+
+// dsnPasswordParams are the query parameters of DSN that hold a password
+var dsnPasswordParams = []string{"password", "sslpassword"}
+
+// MaskDSN return DSN that the password is replaced with the reference to the environment variable
+func MaskDSN(dsn, env string) string {
+	u, err := url.Parse(escapeUserinfo(dsn))
+	if err != nil || u.Scheme == "" {
+		return dsn
+	}
+	ref := fmt.Sprintf("${%s}", env)
+	masked := false
+
+	userinfo := ""
+	if u.User != nil {
+		userinfo = u.User.String()
+		if _, ok := u.User.Password(); ok {
+			userinfo = fmt.Sprintf("%s:%s", url.User(u.User.Username()).String(), ref)
+			masked = true
+		}
+	}
+
+	params := []string{}
+	if u.RawQuery != "" {
+		params = strings.Split(u.RawQuery, "&")
+	}
+	for i, p := range params {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		k, err := url.QueryUnescape(kv[0])
+		if err != nil {
+			continue
+		}
+		for _, pp := range dsnPasswordParams {
+			if strings.EqualFold(k, pp) {
+				params[i] = fmt.Sprintf("%s=%s", kv[0], ref)
+				masked = true
+			}
+		}
+	}
+
+	if !masked {
+		return dsn
+	}
+	var b strings.Builder
+	b.WriteString(u.Scheme)
+	b.WriteString(":")
+	if u.Opaque != "" {
+		b.WriteString(u.Opaque)
+	} else {
+		b.WriteString("//")
+		if u.User != nil {
+			b.WriteString(userinfo)
+			b.WriteString("@")
+		}
+		b.WriteString(u.Host)
+		b.WriteString(u.EscapedPath())
+	}
+	if len(params) > 0 || u.ForceQuery {
+		b.WriteString("?")
+		b.WriteString(strings.Join(params, "&"))
+	}
+	if u.Fragment != "" {
+		b.WriteString("#")
+		b.WriteString(u.EscapedFragment())
+	}
+	return b.String()
+}
+
+// escapeUserinfo return DSN that the literal '@' in userinfo is percent-encoded so that net/url can parse it
+func escapeUserinfo(dsn string) string {
+	i := strings.Index(dsn, "://")
+	if i < 0 {
+		return dsn
+	}
+	authority := dsn[i+3:]
+	if end := strings.IndexAny(authority, "/?#"); end >= 0 {
+		authority = authority[:end]
+	}
+	at := strings.LastIndex(authority, "@")
+	if at < 0 {
+		return dsn
+	}
+	userinfo := strings.Replace(authority[:at], "@", "%40", -1)
+	return dsn[:i+3] + userinfo + dsn[i+3+at:]
+}
+
+// Scaffold return the starter config file ( .tbls.yml ) for the database.
+// The password in DSN is replaced with the reference to env.
+func Scaffold(dsn []string, env string, s *schema.Schema) ([]byte, error) {
+	header := "# tbls config generated by 'tbls init'\n# See https://github.com/Melsoft-Games/tbls#configuration\n"
+	masked := []string{}
+	for _, d := range dsn {
+		masked = append(masked, MaskDSN(d, env))
+	}
+	if strings.Join(masked, ";") != strings.Join(dsn, ";") {
+		header += fmt.Sprintf("#\n# Set the database password to $%s\n", env)
+	}
+	exclude := SuggestExclude(s)
+
+	doc := yaml.MapSlice{
+		{Key: "dsn", Value: masked},
+		{Key: "docPath", Value: defaultDocPath},
+	}
+	if len(exclude) > 0 {
+		doc = append(doc, yaml.MapItem{Key: "exclude", Value: exclude})
+	}
+	doc = append(doc, yaml.MapSlice{
+		{Key: "lint", Value: scaffoldLint(s)},
+		{Key: "comments", Value: scaffoldComments(s, exclude)},
+	}...)
+
+	b, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append([]byte(header), b...), nil
+}
+
+// SuggestExclude return the names and patterns of temporary tables and date-sharded tables
+func SuggestExclude(s *schema.Schema) []string {
+	candidates := map[string][]*schema.Table{}
+	shards := map[string][]*schema.Table{}
+	for _, t := range s.Tables {
+		if tempTableRe.MatchString(baseName(t.Name)) {
+			candidates[t.Name] = []*schema.Table{t}
+			continue
+		}
+		m := shardTableRe.FindStringSubmatch(t.Name)
+		if m == nil {
+			continue
+		}
+		p := fmt.Sprintf("/^%s%s%s$/", regexp.QuoteMeta(m[1]), m[2], digitsRe.ReplaceAllStringFunc(m[3], func(d string) string {
+			return fmt.Sprintf(`\d{%d}`, len(d))
+		}))
+		shards[p] = append(shards[p], t)
+	}
+	for p, tables := range shards {
+		if len(tables) > 1 {
+			candidates[p] = tables
+		}
+	}
+
+	excluded := map[string]bool{}
+	for _, tables := range candidates {
+		for _, t := range tables {
+			excluded[t.Name] = true
+		}
+	}
+	// tables referred by tables that are not excluded are kept
+	referred := map[string]bool{}
+	for _, r := range s.Relations {
+		if excluded[r.ParentTable.Name] && !excluded[r.Table.Name] {
+			referred[r.ParentTable.Name] = true
+		}
+	}
+	suggested := []string{}
+	for name, tables := range candidates {
+		keep := false
+		for _, t := range tables {
+			if referred[t.Name] {
+				keep = true
+			}
+		}
+		if !keep {
+			suggested = append(suggested, name)
+		}
+	}
+	sort.Strings(suggested)
+	return suggested
+}
+
+// scaffoldLint return lint rules that are all disabled with default values
+func scaffoldLint(s *schema.Schema) Lint {
+	exclude := []string{}
+	for _, n := range []string{"id", "created_at", "updated_at"} {
+		for _, t := range s.Tables {
+			if _, err := t.FindColumnByName(n); err == nil {
+				exclude = append(exclude, n)
+				break
+			}
+		}
+	}
+	return Lint{
+		RequireTableComment: RequireTableComment{},
+		RequireColumnComment: RequireColumnComment{
+			Exclude: exclude,
+		},
+		UnrelatedTable: UnrelatedTable{},
+		ColumnCount: ColumnCount{
+			Max: 30,
+		},
+		RequireColumns: RequireColumns{
+			Columns: []RequireColumnsColumn{
+				{Name: "id"},
+			},
+		},
+		DuplicateRelations:     DuplicateRelations{},
+		RequireForeignKeyIndex: RequireForeignKeyIndex{},
+	}
+}
+
+// scaffoldComments return placeholder comments for tables without table comment
+func scaffoldComments(s *schema.Schema, exclude []string) []AdditionalComment {
+	comments := []AdditionalComment{}
+	for _, t := range s.Tables {
+		if t.Comment != "" || t.External || contains(exclude, t.Name) {
+			continue
+		}
+		c := AdditionalComment{
+			Table:          t.Name,
+			TableComment:   scaffoldTableComment,
+			ColumnComments: map[string]string{},
+		}
+		for _, col := range t.Columns {
+			if col.Comment == "" {
+				c.ColumnComments[col.Name] = scaffoldColumnComment
+			}
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// baseName return the table name without the schema name
+func baseName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
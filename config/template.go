@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// LoadTemplate return the template loaded from path ( see Templates ).
+// When path is empty, the built-in template `name` is loaded by builtin ( e.g. packr.Box.FindString ).
+func LoadTemplate(name, path string, builtin func(string) (string, error), funcs template.FuncMap) (*template.Template, error) {
+	ts := ""
+	src := fmt.Sprintf("built-in template '%s'", name)
+	if path == "" {
+		var err error
+		ts, err = builtin(name)
+		if err != nil {
+			return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to load %s", src))
+		}
+	} else {
+		src = fmt.Sprintf("template '%s' ( %s )", name, path)
+		b, err := ioutil.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to load %s", src))
+		}
+		ts = string(b)
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Parse(ts)
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to parse %s", src))
+	}
+	return tmpl, nil
+}
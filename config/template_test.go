@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	builtin := func(name string) (string, error) {
+		if name == "broken.tmpl" {
+			return "{{ .Name ", nil
+		}
+		if name != "hello.tmpl" {
+			return "", fmt.Errorf("%s not found", name)
+		}
+		return "hello {{ .Name | upper }}", nil
+	}
+	funcs := template.FuncMap{"upper": strings.ToUpper, "nl2space": strings.TrimSpace}
+	custom := filepath.Join(testdataDir(), "templates", "table.md.tmpl")
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr string
+	}{
+		{"hello.tmpl", "", "hello TBLS", ""},
+		{"missing.tmpl", "", "", "failed to load built-in template 'missing.tmpl'"},
+		{"broken.tmpl", "", "", "failed to parse built-in template 'broken.tmpl'"},
+		{"schema.dot.tmpl", filepath.Join(testdataDir(), "templates", "schema.dot.tmpl"), "", ""},
+		{"table.md.tmpl", custom, "", fmt.Sprintf("failed to parse template 'table.md.tmpl' ( %s )", custom)},
+		{"table.md.tmpl", "missing.md.tmpl", "", "failed to load template 'table.md.tmpl' ( missing.md.tmpl )"},
+	}
+	for _, tt := range tests {
+		tmpl, err := LoadTemplate(tt.name, tt.path, builtin, funcs)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s %s: got %v\nwant %v", tt.name, tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.name, tt.path, err)
+			continue
+		}
+		if tt.want == "" {
+			continue
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, map[string]string{"Name": "tbls"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("got %v\nwant %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// configFile is the config file that has `extends:` and `profiles:` in addition to Config
+type configFile struct {
+	Config   `yaml:",inline"`
+	Extends  interface{}       `yaml:"extends,omitempty"`
+	Profiles map[string]Config `yaml:"profiles,omitempty"`
+}
+
+var (
+	yamlErrorLineRe = regexp.MustCompile(`^line (\d+): (.+)$`)
+	unknownKeyRe    = regexp.MustCompile(`^(field \S+ not found in type |key .+ already set in map$)`)
+)
+
+// decodeStrict decode the config file strictly, and report unknown keys and invalid values with line numbers.
+// Unless strict, unknown ( and duplicated ) keys are returned as warnings instead of the error.
+func decodeStrict(buf []byte, path string, strict bool) ([]string, error) {
+	err := yaml.UnmarshalStrict(buf, &configFile{})
+	if err == nil {
+		return nil, nil
+	}
+	te, ok := err.(*yaml.TypeError)
+	if !ok {
+		return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("invalid config '%s'", path))
+	}
+	msgs := []string{}
+	warnings := []string{}
+	for _, e := range te.Errors {
+		msg := fmt.Sprintf("%s: %s", path, e)
+		m := yamlErrorLineRe.FindStringSubmatch(e)
+		if m != nil {
+			msg = fmt.Sprintf("%s:%s: %s", path, m[1], m[2])
+		}
+		if !strict && m != nil && unknownKeyRe.MatchString(m[2]) {
+			warnings = append(warnings, msg)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return warnings, nil
+	}
+	return warnings, errors.New(fmt.Sprintf("invalid config:\n  %s", strings.Join(msgs, "\n  ")))
+}
+
+// ValidateSchema validate that tables and columns referenced in config exist in schema.Schema.
+// It reports all problems instead of failing on the first one.
+func (c *Config) ValidateSchema(s *schema.Schema) []error {
+	v := &validator{s: s}
+	v.tables("exclude", c.Exclude)
+	v.tables("include", c.Include)
+	v.tables("lintExclude", c.LintExclude)
+	v.tables("lint.requireTableComment.exclude", c.Lint.RequireTableComment.Exclude)
+	v.columns("lint.requireColumnComment.exclude", c.Lint.RequireColumnComment.Exclude)
+	v.tables("lint.requireColumnComment.excludedTables", c.Lint.RequireColumnComment.ExcludedTables)
+	v.tables("lint.unrelatedTable.exclude", c.Lint.UnrelatedTable.Exclude)
+	v.tables("lint.columnCount.exclude", c.Lint.ColumnCount.Exclude)
+	for i, rc := range c.Lint.RequireColumns.Columns {
+		v.tables(fmt.Sprintf("lint.requireColumns.columns[%d].exclude", i), rc.Exclude)
+	}
+	v.columns("lint.requireForeignKeyIndex.exclude", c.Lint.RequireForeignKeyIndex.Exclude)
+
+	for i, r := range c.Relations {
+		target := fmt.Sprintf("relations[%d]", i)
+		tables := v.table(target+".table", r.Table)
+		for _, t := range tables {
+			for _, col := range r.Columns {
+				if _, err := t.FindColumnByName(col); err != nil && !isPattern(r.Table) {
+					v.errorf(target+".columns", "column '%s.%s' not found", t.Name, col)
+				}
+			}
+		}
+		parents := v.table(target+".parentTable", r.ParentTable)
+		if isPattern(r.ParentTable) && len(parents) > 1 {
+			v.errorf(target+".parentTable", "pattern '%s' matches %d tables", r.ParentTable, len(parents))
+		}
+		for _, t := range parents {
+			for _, col := range r.ParentColumns {
+				if _, err := t.FindColumnByName(col); err != nil {
+					v.errorf(target+".parentColumns", "column '%s.%s' not found", t.Name, col)
+				}
+			}
+		}
+	}
+
+	for i, ac := range c.Comments {
+		target := fmt.Sprintf("comments[%d]", i)
+		tables := v.table(target+".table", ac.Table)
+		keys := []string{}
+		for k := range ac.ColumnComments {
+			keys = append(keys, k)
+		}
+		v.tableColumns(target+".columnComments", tables, patternsFirst(keys), !isPattern(ac.Table))
+		keys = []string{}
+		for k := range ac.ColumnLabels {
+			keys = append(keys, k)
+		}
+		v.tableColumns(target+".columnLabels", tables, patternsFirst(keys), !isPattern(ac.Table))
+	}
+
+	for i, vp := range c.Viewpoints {
+		v.tables(fmt.Sprintf("viewpoints[%d].tables", i), vp.Tables)
+	}
+	return v.errs
+}
+
+type validator struct {
+	s    *schema.Schema
+	errs []error
+}
+
+func (v *validator) errorf(target, format string, a ...interface{}) {
+	v.errs = append(v.errs, errors.New(fmt.Sprintf("%s: %s", target, fmt.Sprintf(format, a...))))
+}
+
+// table return tables that match the name or pattern, and report the name that does not exist or the pattern that matches nothing
+func (v *validator) table(target, name string) []*schema.Table {
+	tables, err := findTables(v.s, name)
+	if err != nil {
+		v.errorf(target, "table '%s' not found", name)
+		return nil
+	}
+	if len(tables) == 0 {
+		v.errorf(target, "pattern '%s' matches no table", name)
+	}
+	return tables
+}
+
+func (v *validator) tables(target string, names []string) {
+	for _, n := range names {
+		v.table(target, n)
+	}
+}
+
+// columns report column names ( or `table.column` ) that do not exist in any table
+func (v *validator) columns(target string, names []string) {
+	for _, n := range names {
+		found := false
+		for _, t := range v.s.Tables {
+			for _, c := range t.Columns {
+				if matchName(n, c.Name) || matchName(n, fmt.Sprintf("%s.%s", t.Name, c.Name)) {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			v.errorf(target, "column '%s' not found", n)
+		}
+	}
+}
+
+func (v *validator) tableColumns(target string, tables []*schema.Table, names []string, strict bool) {
+	for _, n := range names {
+		found := false
+		for _, t := range tables {
+			columns, err := findColumns(t, n, strict)
+			if err != nil {
+				v.errorf(target, "column '%s.%s' not found", t.Name, n)
+				found = true
+				continue
+			}
+			if len(columns) > 0 {
+				found = true
+			}
+		}
+		if found || len(tables) == 0 {
+			continue
+		}
+		if isPattern(n) {
+			v.errorf(target, "pattern '%s' matches no column", n)
+		} else {
+			v.errorf(target, "column '%s' not found", n)
+		}
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/Melsoft-Games/tbls/drivers"
 	"github.com/Melsoft-Games/tbls/drivers/bq"
+	"github.com/Melsoft-Games/tbls/drivers/dbml"
 	"github.com/Melsoft-Games/tbls/drivers/mysql"
 	"github.com/Melsoft-Games/tbls/drivers/postgres"
 	"github.com/Melsoft-Games/tbls/schema"
@@ -33,6 +36,9 @@ func AnalyzeImpl(urlstr string, s *schema.Schema) error {
 	if strings.Index(urlstr, "json://") == 0 {
 		return AnalizeJSON(urlstr, s)
 	}
+	if strings.Index(urlstr, "dbml://") == 0 {
+		return AnalizeDBML(urlstr, s)
+	}
 	if strings.Index(urlstr, "bq://") == 0 || strings.Index(urlstr, "bigquery://") == 0 {
 		return AnalizeBigquery(urlstr, s)
 	}
@@ -97,6 +103,30 @@ func AnalizeJSON(urlstr string, s *schema.Schema) error {
 	return nil
 }
 
+// AnalizeDBML analyze `dbml://`
+func AnalizeDBML(urlstr string, s *schema.Schema) error {
+	splitted := strings.Split(urlstr, "dbml://")
+	file, err := os.Open(splitted[1])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(splitted[1]), filepath.Ext(splitted[1]))
+	}
+	driver := dbml.NewDbml(file)
+	d, err := driver.Info()
+	if err != nil {
+		return err
+	}
+	s.Driver = d
+	err = driver.Analyze(s)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to analyze %s", splitted[1]))
+	}
+	return nil
+}
+
 // AnalizeBigquery analyze `bq://`
 func AnalizeBigquery(urlstr string, s *schema.Schema) error {
 	u, err := url.Parse(urlstr)
@@ -109,6 +139,13 @@ func AnalizeBigquery(urlstr string, s *schema.Schema) error {
 	if err != nil {
 		return err
 	}
+	collapse := false
+	if v := values.Get("collapse_shards"); v != "" {
+		collapse, err = strconv.ParseBool(v)
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), fmt.Sprintf("invalid collapse_shards '%s'", v))
+		}
+	}
 
 	splitted := strings.Split(u.Path, "/")
 
@@ -127,6 +164,7 @@ func AnalizeBigquery(urlstr string, s *schema.Schema) error {
 	if err != nil {
 		return err
 	}
+	driver.SetCollapseShards(collapse)
 	d, err := driver.Info()
 	if err != nil {
 		return err
@@ -3,8 +3,11 @@ package datasource
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Melsoft-Games/tbls/schema"
+
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 )
@@ -18,6 +21,7 @@ var tests = []struct {
 	{[]string{"my://root:mypass@localhost:33306/testdb"}, "MySQL schema", 9, 6},
 	{[]string{"pg://postgres:pgpass@localhost:55432/testdb?sslmode=disable"}, "Postgres schema", 11, 8},
 	{[]string{"json://../testdata/testdb.json"}, "testdb", 7, 9},
+	{[]string{"dbml://../testdata/testdb.dbml"}, "testdb", 4, 5},
 }
 
 func TestMain(m *testing.M) {
@@ -68,6 +72,14 @@ func TestAnalyzeTables(t *testing.T) {
 	}
 }
 
+func TestAnalyzeBigqueryCollapseShards(t *testing.T) {
+	s := &schema.Schema{}
+	err := AnalizeBigquery("bq://project-id/dataset-id?collapse_shards=maybe", s)
+	if err == nil || !strings.Contains(err.Error(), "invalid collapse_shards 'maybe'") {
+		t.Errorf("got %v\nwant invalid collapse_shards error", err)
+	}
+}
+
 func TestAnalyzeRelations(t *testing.T) {
 	for _, tt := range tests {
 		schema, err := Analyze(tt.dsn)
@@ -3,7 +3,10 @@ package bq
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/Melsoft-Games/tbls/schema"
@@ -11,9 +14,10 @@ import (
 
 // Bigquery struct
 type Bigquery struct {
-	ctx       context.Context
-	client    *bigquery.Client
-	datasetID string
+	ctx            context.Context
+	client         *bigquery.Client
+	datasetID      string
+	collapseShards bool
 }
 
 // NewBigquery return new Bigquery
@@ -25,6 +29,11 @@ func NewBigquery(ctx context.Context, client *bigquery.Client, datasetID string)
 	}, nil
 }
 
+// SetCollapseShards set whether date-sharded tables are collapsed into wildcard tables ( see collapseShards )
+func (b *Bigquery) SetCollapseShards(collapse bool) {
+	b.collapseShards = collapse
+}
+
 func (b *Bigquery) Analyze(s *schema.Schema) error {
 	bt := b.client.Dataset(b.datasetID).Tables(b.ctx)
 
@@ -48,14 +57,108 @@ func (b *Bigquery) Analyze(s *schema.Schema) error {
 			Comment: m.Description,
 			Type:    string(m.Type),
 			Def:     m.ViewQuery,
+			Labels:  listLabels(m.Labels),
 			Columns: listColumns(m.Schema, ""),
 		}
 
 		s.Tables = append(s.Tables, table)
 	}
+	if b.collapseShards {
+		s.Tables = collapseShards(s.Tables)
+	}
 	return nil
 }
 
+var shardRe = regexp.MustCompile(`^(.*\D)(\d{8})$`)
+
+// collapseShards collapse date-sharded tables ( e.g. `events_20200101` ... `events_20201231` )
+// that have the same columns into one wildcard table ( e.g. `events_*` ).
+// Comment, labels and columns of the wildcard table are of the latest shard.
+func collapseShards(tables []*schema.Table) []*schema.Table {
+	groups := map[string][]*schema.Table{}
+	for _, t := range tables {
+		prefix, ok := shardPrefix(t.Name)
+		if !ok {
+			continue
+		}
+		groups[prefix] = append(groups[prefix], t)
+	}
+
+	collapsed := []*schema.Table{}
+	done := map[string]bool{}
+	for _, t := range tables {
+		prefix, ok := shardPrefix(t.Name)
+		if !ok || len(groups[prefix]) < 2 || !sameColumns(groups[prefix]) {
+			collapsed = append(collapsed, t)
+			continue
+		}
+		if done[prefix] {
+			continue
+		}
+		done[prefix] = true
+		shards := groups[prefix]
+		sort.Slice(shards, func(i, j int) bool {
+			return shards[i].Name < shards[j].Name
+		})
+		latest := shards[len(shards)-1]
+		collapsed = append(collapsed, &schema.Table{
+			Name:    fmt.Sprintf("%s*", prefix),
+			Comment: latest.Comment,
+			Type:    latest.Type,
+			Labels:  latest.Labels,
+			Columns: latest.Columns,
+			Shard: &schema.Shard{
+				First: strings.TrimPrefix(shards[0].Name, prefix),
+				Last:  strings.TrimPrefix(latest.Name, prefix),
+				Count: len(shards),
+			},
+		})
+	}
+	return collapsed
+}
+
+// shardPrefix return the prefix of date-sharded table name ( e.g. `events_20200101` -> `events_` )
+func shardPrefix(name string) (string, bool) {
+	m := shardRe.FindStringSubmatch(name)
+	if m == nil {
+		return "", false
+	}
+	if _, err := time.Parse("20060102", m[2]); err != nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// sameColumns return true if the tables have the same column names and types
+func sameColumns(tables []*schema.Table) bool {
+	for _, t := range tables[1:] {
+		if len(t.Columns) != len(tables[0].Columns) {
+			return false
+		}
+		for i, c := range t.Columns {
+			b := tables[0].Columns[i]
+			if c.Name != b.Name || c.Type != b.Type || c.Nullable != b.Nullable || c.Repeated != b.Repeated {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// listLabels return table labels sorted by key
+func listLabels(m map[string]string) schema.Labels {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	labels := schema.Labels{}
+	for _, k := range keys {
+		labels = append(labels, &schema.Label{Name: k, Value: m[k]})
+	}
+	return labels
+}
+
 func listColumns(s bigquery.Schema, prefix string) []*schema.Column {
 	columns := []*schema.Column{}
 	for _, c := range s {
@@ -64,8 +167,8 @@ func listColumns(s bigquery.Schema, prefix string) []*schema.Column {
 			Name:     name,
 			Comment:  c.Description,
 			Nullable: !c.Required,
+			Repeated: c.Repeated,
 			Type:     string(c.Type),
-			// TODO: c.Repeated
 		}
 		columns = append(columns, column)
 		if len(c.Schema) > 0 {
@@ -33,6 +33,80 @@ func TestAnalyzeView(t *testing.T) {
 	}
 }
 
+func TestListLabels(t *testing.T) {
+	labels := listLabels(map[string]string{
+		"team":      "growth",
+		"retention": "90d",
+		"pii":       "",
+	})
+	want := "[pii retention: 90d team: growth]"
+	if got := fmt.Sprintf("%v", labels); got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestCollapseShards(t *testing.T) {
+	columns := func(typ string) []*schema.Column {
+		return []*schema.Column{
+			&schema.Column{Name: "event_name", Type: "STRING"},
+			&schema.Column{Name: "user_id", Type: typ},
+		}
+	}
+	tables := []*schema.Table{
+		&schema.Table{Name: "dataset.users", Columns: columns("STRING")},
+		&schema.Table{Name: "dataset.events_20200102", Comment: "latest", Columns: columns("STRING")},
+		&schema.Table{Name: "dataset.events_20200101", Comment: "first", Columns: columns("STRING")},
+		&schema.Table{Name: "dataset.events_intraday_20200103", Columns: columns("STRING")},
+		&schema.Table{Name: "dataset.logs_20200101", Columns: columns("STRING")},
+		&schema.Table{Name: "dataset.logs_20200102", Columns: columns("INT64")},
+		&schema.Table{Name: "dataset.backup_99999999", Columns: columns("STRING")},
+		&schema.Table{Name: "dataset.backup_99999998", Columns: columns("STRING")},
+	}
+	got := collapseShards(tables)
+	names := []string{}
+	for _, tbl := range got {
+		names = append(names, tbl.Name)
+	}
+	want := "[dataset.users dataset.events_* dataset.events_intraday_20200103 dataset.logs_20200101 dataset.logs_20200102 dataset.backup_99999999 dataset.backup_99999998]"
+	if fmt.Sprintf("%v", names) != want {
+		t.Errorf("got %v\nwant %v", names, want)
+	}
+	events := got[1]
+	if events.Comment != "latest" {
+		t.Errorf("got %v\nwant %v", events.Comment, "latest")
+	}
+	if want := (schema.Shard{First: "20200101", Last: "20200102", Count: 2}); *events.Shard != want {
+		t.Errorf("got %v\nwant %v", *events.Shard, want)
+	}
+}
+
+func TestCollapseShardsDifferentSchemas(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed *schema.Column
+	}{
+		{"type", &schema.Column{Name: "tags", Type: "INT64", Repeated: true}},
+		{"nullable", &schema.Column{Name: "tags", Type: "STRING", Nullable: true, Repeated: true}},
+		{"repeated", &schema.Column{Name: "tags", Type: "STRING"}},
+	}
+	for _, tt := range tests {
+		tables := []*schema.Table{
+			&schema.Table{Name: "dataset.events_20200101", Columns: []*schema.Column{
+				&schema.Column{Name: "event_name", Type: "STRING"},
+				&schema.Column{Name: "tags", Type: "STRING", Repeated: true},
+			}},
+			&schema.Table{Name: "dataset.events_20200102", Columns: []*schema.Column{
+				&schema.Column{Name: "event_name", Type: "STRING"},
+				tt.changed,
+			}},
+		}
+		got := collapseShards(tables)
+		if len(got) != 2 {
+			t.Errorf("%s: got %d tables\nwant %d", tt.name, len(got), 2)
+		}
+	}
+}
+
 func initClient(t *testing.T) (context.Context, *bigquery.Client) {
 	cPath := credentialPath()
 	if _, err := os.Lstat(cPath); err != nil {
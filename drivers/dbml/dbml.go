@@ -0,0 +1,136 @@
+package dbml
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// Dbml struct
+type Dbml struct {
+	r io.Reader
+}
+
+// NewDbml return new Dbml
+func NewDbml(r io.Reader) *Dbml {
+	return &Dbml{
+		r: r,
+	}
+}
+
+// Analyze DBML ( https://www.dbml.org/ ) source
+func (d *Dbml) Analyze(s *schema.Schema) error {
+	src, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	p, err := parse(string(src))
+	if err != nil {
+		return err
+	}
+	if p.name != "" {
+		s.Name = p.name
+	}
+	s.Tables = append(s.Tables, p.tables...)
+
+	for _, r := range p.refs {
+		relation, err := p.relation(s, r)
+		if err != nil {
+			return err
+		}
+		s.Relations = append(s.Relations, relation)
+	}
+	return nil
+}
+
+// Info return schema.Driver
+func (d *Dbml) Info() (*schema.Driver, error) {
+	dct := &schema.Driver{
+		Name:            "dbml",
+		DatabaseVersion: "",
+	}
+	return dct, nil
+}
+
+func (p *parser) relation(s *schema.Schema, r ref) (*schema.Relation, error) {
+	table, columns, err := p.resolve(s, r.child, r.line)
+	if err != nil {
+		return nil, err
+	}
+	parentTable, parentColumns, err := p.resolve(s, r.parent, r.line)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) != len(parentColumns) {
+		return nil, errors.WithStack(fmt.Errorf("line %d: column count mismatch in relation '%s' -> '%s'", r.line, table.Name, parentTable.Name))
+	}
+	names := []string{}
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	parentNames := []string{}
+	for _, c := range parentColumns {
+		parentNames = append(parentNames, c.Name)
+	}
+	def := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)", strings.Join(names, ", "), parentTable.Name, strings.Join(parentNames, ", "))
+
+	relation := &schema.Relation{
+		Table:         table,
+		Columns:       columns,
+		ParentTable:   parentTable,
+		ParentColumns: parentColumns,
+		Def:           def,
+		Virtual:       r.virtual,
+	}
+	if r.virtual {
+		relation.Def = r.def
+		if relation.Def == "" {
+			relation.Def = "Additional Relation"
+		}
+	} else {
+		name := r.name
+		if name == "" {
+			name = fmt.Sprintf("%s_%s_fkey", table.Name, strings.Join(names, "_"))
+		}
+		table.Constraints = append(table.Constraints, &schema.Constraint{
+			Name:             name,
+			Type:             schema.TypeFK,
+			Def:              def,
+			Table:            &table.Name,
+			ReferenceTable:   &parentTable.Name,
+			Columns:          names,
+			ReferenceColumns: parentNames,
+		})
+	}
+	for _, c := range columns {
+		c.ParentRelations = append(c.ParentRelations, relation)
+	}
+	for _, c := range parentColumns {
+		c.ChildRelations = append(c.ChildRelations, relation)
+	}
+	return relation, nil
+}
+
+func (p *parser) resolve(s *schema.Schema, e endpoint, line int) (*schema.Table, []*schema.Column, error) {
+	name := e.table
+	if n, ok := p.aliases[name]; ok {
+		name = n
+	}
+	t, err := s.FindTableByName(name)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("line %d: failed to add relation", line))
+	}
+	columns := []*schema.Column{}
+	for _, n := range e.columns {
+		c, err := t.FindColumnByName(n)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, fmt.Sprintf("line %d: failed to add relation", line))
+		}
+		columns = append(columns, c)
+	}
+	return t, columns, nil
+}
@@ -0,0 +1,132 @@
+package dbml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestAnalyze(t *testing.T) {
+	s := analyze(t, "testdb.dbml")
+	if want := "testdb"; s.Name != want {
+		t.Errorf("actual %v\nwant %v", s.Name, want)
+	}
+	if want := 4; len(s.Tables) != want {
+		t.Errorf("actual %v\nwant %v", len(s.Tables), want)
+	}
+	if want := 5; len(s.Relations) != want {
+		t.Errorf("actual %v\nwant %v", len(s.Relations), want)
+	}
+
+	users, _ := s.FindTableByName("users")
+	if want := "Users table"; users.Comment != want {
+		t.Errorf("actual %v\nwant %v", users.Comment, want)
+	}
+	id, _ := users.FindColumnByName("id")
+	if id.Nullable {
+		t.Errorf("primary key should not be nullable")
+	}
+	if want := 3; len(id.ChildRelations) != want {
+		t.Errorf("actual %v\nwant %v", len(id.ChildRelations), want)
+	}
+	email, _ := users.FindColumnByName("email")
+	if want := "character varying(355)"; email.Type != want {
+		t.Errorf("actual %v\nwant %v", email.Type, want)
+	}
+	created, _ := users.FindColumnByName("created")
+	if want := "now()"; created.Default.String != want {
+		t.Errorf("actual %v\nwant %v", created.Default.String, want)
+	}
+
+	posts, _ := s.FindTableByName("posts")
+	if want := "Posts table\nMulti-line"; posts.Comment != want {
+		t.Errorf("actual %v\nwant %v", posts.Comment, want)
+	}
+	if want := 3; len(posts.Indexes) != want {
+		t.Errorf("actual %v\nwant %v", len(posts.Indexes), want)
+	}
+	if want := []string{"PRIMARY KEY", "UNIQUE", schema.TypeFK}; len(posts.Constraints) != len(want) {
+		t.Errorf("actual %v\nwant %v", len(posts.Constraints), len(want))
+	}
+	postType, _ := posts.FindColumnByName("post_type")
+	if want := "'draft'"; postType.Default.String != want {
+		t.Errorf("actual %v\nwant %v", postType.Default.String, want)
+	}
+
+	comments, _ := s.FindTableByName("comments")
+	score, _ := comments.FindColumnByName("score")
+	if want := "decimal(10, 2)"; score.Type != want {
+		t.Errorf("actual %v\nwant %v", score.Type, want)
+	}
+	if want := "-1.5"; score.Default.String != want {
+		t.Errorf("actual %v\nwant %v", score.Default.String, want)
+	}
+	for _, c := range comments.Constraints {
+		if c.Type == schema.TypeFK && c.Columns[0] == "post_id" && c.Name != "comments_post_id_fkey" {
+			t.Errorf("actual %v\nwant %v", c.Name, "comments_post_id_fkey")
+		}
+	}
+
+	logs, err := s.FindTableByName("audit.logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID, _ := logs.FindColumnByName("user_id")
+	r := userID.ParentRelations[0]
+	if !r.Virtual || r.Def != "logs->users" {
+		t.Errorf("actual %v %v\nwant %v %v", r.Virtual, r.Def, true, "logs->users")
+	}
+	if len(logs.Constraints) != 1 {
+		t.Errorf("virtual relations should not add constraints: %v", logs.Constraints)
+	}
+}
+
+var errorTests = []struct {
+	src  string
+	want string
+}{
+	{"Table a {\n  id int\n", "line 1: '{' is not closed"},
+	{"Table a {\n  id int [pk\n}\n", "line 2: '[' is not closed"},
+	{"Table a {\n  id int pk\n}\n", "line 2: unexpected 'pk', expecting end of line"},
+	{"Table a {\n  id int\n}\nRef: a.id > b.id\n", "line 4: failed to add relation"},
+	{"Index a {\n}\n", "line 1: unexpected 'Index'"},
+	{"Table a {\n  id int\n  b_id int [ref: ]\n}\n", "line 3: unexpected end of file"},
+	{"Table a {\n  id int\n  b_id int [ref: > b.id]\n}\n", "line 3: failed to add relation"},
+}
+
+func TestAnalyzeError(t *testing.T) {
+	for _, tt := range errorTests {
+		s := &schema.Schema{}
+		err := NewDbml(strings.NewReader(tt.src)).Analyze(s)
+		if err == nil {
+			t.Errorf("%q: want error", tt.src)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("actual %v\nwant %v", err.Error(), tt.want)
+		}
+	}
+}
+
+func analyze(t *testing.T, name string) *schema.Schema {
+	f, err := os.Open(filepath.Join(testdataDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s := &schema.Schema{}
+	err = NewDbml(f).Analyze(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
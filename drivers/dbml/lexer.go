@@ -0,0 +1,163 @@
+package dbml
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenWord    // bare identifier, keyword or number
+	tokenQuoted  // "quoted identifier"
+	tokenString  // 'string' or '''multi-line string'''
+	tokenExpr    // `expression`
+	tokenPunct   // { } [ ] ( ) , : . < > -
+	tokenComment // comment text without `//`
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+}
+
+func (t token) is(kind tokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+// isName return true if the token can be used as name of table, column and so on
+func (t token) isName() bool {
+	return t.kind == tokenWord || t.kind == tokenQuoted
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenNewline:
+		return "end of line"
+	}
+	return fmt.Sprintf("'%s'", t.value)
+}
+
+const puncts = "{}[](),:.<>-"
+
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	rs := []rune(src)
+	line := 1
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case r == '\n':
+			tokens = append(tokens, token{kind: tokenNewline, line: line})
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			j := i + 2
+			for j < len(rs) && rs[j] != '\n' {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenComment, value: strings.TrimSpace(string(rs[i+2 : j])), line: line})
+			i = j
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			j := i + 2
+			for j+1 < len(rs) && !(rs[j] == '*' && rs[j+1] == '/') {
+				if rs[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			if j+1 >= len(rs) {
+				return nil, errors.WithStack(fmt.Errorf("line %d: unterminated comment", line))
+			}
+			i = j + 2
+		case r == '\'' && i+2 < len(rs) && rs[i+1] == '\'' && rs[i+2] == '\'':
+			start := line
+			j := i + 3
+			var b strings.Builder
+			for ; j < len(rs); j++ {
+				if rs[j] == '\'' && j+2 < len(rs) && rs[j+1] == '\'' && rs[j+2] == '\'' {
+					break
+				}
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+				}
+				if rs[j] == '\n' {
+					line++
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, errors.WithStack(fmt.Errorf("line %d: unterminated string", start))
+			}
+			tokens = append(tokens, token{kind: tokenString, value: dedent(b.String()), line: start})
+			i = j + 3
+		case r == '\'' || r == '"' || r == '`':
+			kind := map[rune]tokenKind{'\'': tokenString, '"': tokenQuoted, '`': tokenExpr}[r]
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(rs) && rs[j] != r; j++ {
+				if rs[j] == '\n' {
+					return nil, errors.WithStack(fmt.Errorf("line %d: unterminated string", line))
+				}
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, errors.WithStack(fmt.Errorf("line %d: unterminated string", line))
+			}
+			tokens = append(tokens, token{kind: kind, value: b.String(), line: line})
+			i = j + 1
+		case strings.ContainsRune(puncts, r):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(r), line: line})
+			i++
+		default:
+			j := i
+			for j < len(rs) && !unicode.IsSpace(rs[j]) && !strings.ContainsRune(puncts+`'"`+"`", rs[j]) && !(rs[j] == '/' && j+1 < len(rs) && (rs[j+1] == '/' || rs[j+1] == '*')) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(rs[i:j]), line: line})
+			i = j
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
+
+// dedent remove the common indentation of multi-line string like DBML does
+func dedent(v string) string {
+	lines := strings.Split(strings.Replace(v, "\r\n", "\n", -1), "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
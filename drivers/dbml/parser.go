@@ -0,0 +1,667 @@
+package dbml
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// setting is an element of `[...]` settings.
+// Flags ( e.g. `pk`, `not null` ) have no value.
+type setting struct {
+	key    string
+	values []token
+	line   int
+}
+
+func (s setting) value() string {
+	vs := []string{}
+	for _, v := range s.values {
+		vs = append(vs, v.value)
+	}
+	return strings.Join(vs, "")
+}
+
+// endpoint is a side of relationship
+type endpoint struct {
+	table   string
+	columns []string
+}
+
+type ref struct {
+	name    string
+	child   endpoint
+	parent  endpoint
+	virtual bool
+	def     string
+	line    int
+}
+
+type parser struct {
+	tokens  []token
+	pos     int
+	name    string
+	tables  []*schema.Table
+	aliases map[string]string
+	refs    []ref
+}
+
+func parse(src string) (*parser, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		tokens:  tokens,
+		aliases: map[string]string{},
+	}
+	err = p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// skip skip newlines and comments
+func (p *parser) skip() {
+	for p.peek().kind == tokenNewline || p.peek().kind == tokenComment {
+		p.pos++
+	}
+}
+
+// skipComments skip comments but newlines
+func (p *parser) skipComments() {
+	for p.peek().kind == tokenComment {
+		p.pos++
+	}
+}
+
+func (p *parser) expect(kind tokenKind, value string) (token, error) {
+	t := p.next()
+	if t.kind != kind || (value != "" && t.value != value) {
+		want := value
+		if want == "" {
+			want = map[tokenKind]string{tokenWord: "name", tokenString: "string"}[kind]
+		}
+		return t, p.errorf(t, "unexpected %s, expecting '%s'", t, want)
+	}
+	return t, nil
+}
+
+func (p *parser) errorf(t token, format string, a ...interface{}) error {
+	return errors.WithStack(fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, a...)))
+}
+
+func (p *parser) parse() error {
+	for {
+		p.skip()
+		t := p.peek()
+		if t.kind == tokenEOF {
+			return nil
+		}
+		if t.kind != tokenWord {
+			return p.errorf(t, "unexpected %s", t)
+		}
+		var err error
+		switch strings.ToLower(t.value) {
+		case "project":
+			err = p.parseProject()
+		case "table":
+			err = p.parseTable()
+		case "ref":
+			err = p.parseRef()
+		case "enum", "tablegroup":
+			err = p.skipBlock()
+		default:
+			return p.errorf(t, "unexpected %s", t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// skipBlock skip `Keyword name { ... }`
+func (p *parser) skipBlock() error {
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected %s, expecting '{'", t)
+		case t.is(tokenPunct, "{"):
+			return p.skipUntilClose(t)
+		}
+	}
+}
+
+func (p *parser) skipUntilClose(open token) error {
+	depth := 1
+	for depth > 0 {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(open, "'{' is not closed")
+		case t.is(tokenPunct, "{"):
+			depth++
+		case t.is(tokenPunct, "}"):
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseProject() error {
+	p.next()
+	if p.peek().isName() {
+		p.name = p.next().value
+	}
+	return p.skipBlock()
+}
+
+// parseName parse `name` or `schema.name`
+func (p *parser) parseName() (string, error) {
+	parts := []string{}
+	for {
+		t := p.next()
+		if !t.isName() {
+			return "", p.errorf(t, "unexpected %s, expecting name", t)
+		}
+		parts = append(parts, t.value)
+		if !p.peek().is(tokenPunct, ".") {
+			return strings.Join(parts, "."), nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseTable() error {
+	p.next()
+	name, err := p.parseName()
+	if err != nil {
+		return err
+	}
+	table := &schema.Table{
+		Name: name,
+		Type: "BASE TABLE",
+	}
+	if t := p.peek(); t.kind == tokenWord && strings.ToLower(t.value) == "as" {
+		p.next()
+		alias, err := p.expect(tokenWord, "")
+		if err != nil {
+			return err
+		}
+		p.aliases[alias.value] = name
+	}
+	if p.peek().is(tokenPunct, "[") {
+		settings, err := p.parseSettings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			if s.key == "note" {
+				table.Comment = s.value()
+			}
+		}
+	}
+	p.skipComments()
+	open, err := p.expect(tokenPunct, "{")
+	if err != nil {
+		return err
+	}
+	for {
+		p.skip()
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(open, "'{' is not closed")
+		case t.is(tokenPunct, "}"):
+			p.next()
+			p.tables = append(p.tables, table)
+			return nil
+		case t.kind == tokenWord && strings.ToLower(t.value) == "note" && (p.tokens[p.pos+1].is(tokenPunct, ":") || p.tokens[p.pos+1].is(tokenPunct, "{")):
+			table.Comment, err = p.parseNote()
+		case t.kind == tokenWord && strings.ToLower(t.value) == "indexes" && p.tokens[p.pos+1].is(tokenPunct, "{"):
+			err = p.parseIndexes(table)
+		default:
+			err = p.parseColumn(table)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseNote parse `Note: 'string'` or `Note { 'string' }`
+func (p *parser) parseNote() (string, error) {
+	p.next()
+	if p.next().is(tokenPunct, ":") {
+		t, err := p.expect(tokenString, "")
+		return t.value, err
+	}
+	p.skip()
+	t, err := p.expect(tokenString, "")
+	if err != nil {
+		return "", err
+	}
+	p.skip()
+	_, err = p.expect(tokenPunct, "}")
+	return t.value, err
+}
+
+func (p *parser) parseColumn(table *schema.Table) error {
+	name := p.next()
+	if !name.isName() {
+		return p.errorf(name, "unexpected %s, expecting column", name)
+	}
+	typ, err := p.parseType()
+	if err != nil {
+		return err
+	}
+	column := &schema.Column{
+		Name:     name.value,
+		Type:     typ,
+		Nullable: true,
+	}
+	if p.peek().is(tokenPunct, "[") {
+		settings, err := p.parseSettings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			switch s.key {
+			case "pk", "primary key":
+				column.Nullable = false
+				addKey(table, "PRIMARY KEY", "", []string{column.Name})
+			case "unique":
+				addKey(table, "UNIQUE", "", []string{column.Name})
+			case "not null":
+				column.Nullable = false
+			case "null":
+				column.Nullable = true
+			case "note":
+				column.Comment = s.value()
+			case "default":
+				column.Default = sql.NullString{String: defaultValue(s.values), Valid: true}
+			case "ref":
+				r, err := p.inlineRef(table.Name, column.Name, s)
+				if err != nil {
+					return err
+				}
+				p.refs = append(p.refs, r)
+			}
+		}
+	}
+	table.Columns = append(table.Columns, column)
+	return p.endOfLine()
+}
+
+// parseType parse column type like `int`, `varchar(255)`, `decimal(10, 2)`, `int[]` or `"character varying"`
+func (p *parser) parseType() (string, error) {
+	typ, err := p.parseName()
+	if err != nil {
+		return "", err
+	}
+	if p.peek().is(tokenPunct, "(") {
+		args := []string{}
+		p.next()
+		for {
+			t := p.next()
+			if t.is(tokenPunct, ")") {
+				break
+			}
+			switch t.kind {
+			case tokenEOF, tokenNewline:
+				return "", p.errorf(t, "unexpected %s, expecting ')'", t)
+			case tokenString:
+				args = append(args, fmt.Sprintf("'%s'", t.value))
+			case tokenPunct:
+				if t.value == "," {
+					args = append(args, ", ")
+					continue
+				}
+				args = append(args, t.value)
+			default:
+				args = append(args, t.value)
+			}
+		}
+		typ = fmt.Sprintf("%s(%s)", typ, strings.Join(args, ""))
+	}
+	if p.peek().is(tokenPunct, "[") && p.tokens[p.pos+1].is(tokenPunct, "]") {
+		p.pos += 2
+		typ = fmt.Sprintf("%s[]", typ)
+	}
+	return typ, nil
+}
+
+func (p *parser) parseSettings() ([]setting, error) {
+	open := p.next()
+	settings := []setting{}
+	var s *setting
+	words := []string{}
+	line := open.line
+	flush := func() {
+		if s == nil {
+			s = &setting{key: strings.ToLower(strings.Join(words, " ")), line: line}
+		}
+		if s.key != "" {
+			settings = append(settings, *s)
+		}
+		s = nil
+		words = []string{}
+	}
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, p.errorf(open, "'[' is not closed")
+		case t.kind == tokenComment || t.kind == tokenNewline:
+		case t.is(tokenPunct, "]"):
+			flush()
+			return settings, nil
+		case t.is(tokenPunct, ","):
+			flush()
+		case s == nil && t.is(tokenPunct, ":"):
+			s = &setting{key: strings.ToLower(strings.Join(words, " ")), line: line}
+		case s == nil:
+			if len(words) == 0 {
+				line = t.line
+			}
+			words = append(words, t.value)
+		default:
+			s.values = append(s.values, t)
+		}
+	}
+}
+
+func (p *parser) endOfLine() error {
+	p.skipComments()
+	t := p.peek()
+	if t.kind == tokenNewline || t.kind == tokenEOF || t.is(tokenPunct, "}") {
+		return nil
+	}
+	return p.errorf(t, "unexpected %s, expecting end of line", t)
+}
+
+func (p *parser) parseIndexes(table *schema.Table) error {
+	p.next()
+	open := p.next()
+	for {
+		p.skip()
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(open, "'{' is not closed")
+		case t.is(tokenPunct, "}"):
+			p.next()
+			return nil
+		}
+		columns := []string{}
+		if t.is(tokenPunct, "(") {
+			p.next()
+			for {
+				c := p.next()
+				if c.is(tokenPunct, ")") {
+					break
+				}
+				if c.is(tokenPunct, ",") {
+					continue
+				}
+				if !c.isName() && c.kind != tokenExpr {
+					return p.errorf(c, "unexpected %s, expecting column", c)
+				}
+				columns = append(columns, c.value)
+			}
+		} else {
+			c := p.next()
+			if !c.isName() && c.kind != tokenExpr {
+				return p.errorf(c, "unexpected %s, expecting column", c)
+			}
+			columns = append(columns, c.value)
+		}
+		typ := "INDEX"
+		name := ""
+		if p.peek().is(tokenPunct, "[") {
+			settings, err := p.parseSettings()
+			if err != nil {
+				return err
+			}
+			for _, s := range settings {
+				switch s.key {
+				case "pk":
+					typ = "PRIMARY KEY"
+				case "unique":
+					typ = "UNIQUE"
+				case "name":
+					name = s.value()
+				}
+			}
+		}
+		if typ == "INDEX" {
+			addIndex(table, name, fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName(table, name, columns), table.Name, strings.Join(columns, ", ")), columns)
+		} else {
+			addKey(table, typ, name, columns)
+		}
+		err := p.endOfLine()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseRef parse `Ref name: a.b > c.d` or `Ref name { a.b > c.d }`
+func (p *parser) parseRef() error {
+	p.next()
+	name := ""
+	if p.peek().isName() {
+		name = p.next().value
+	}
+	t := p.next()
+	switch {
+	case t.is(tokenPunct, ":"):
+		return p.parseRelationship(name)
+	case t.is(tokenPunct, "{"):
+		for {
+			p.skip()
+			if p.peek().is(tokenPunct, "}") {
+				p.next()
+				return nil
+			}
+			if p.peek().kind == tokenEOF {
+				return p.errorf(t, "'{' is not closed")
+			}
+			err := p.parseRelationship(name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return p.errorf(t, "unexpected %s, expecting ':' or '{'", t)
+}
+
+func (p *parser) parseRelationship(name string) error {
+	line := p.peek().line
+	left, err := p.parseEndpoint()
+	if err != nil {
+		return err
+	}
+	op, err := p.parseOperator()
+	if err != nil {
+		return err
+	}
+	right, err := p.parseEndpoint()
+	if err != nil {
+		return err
+	}
+	if p.peek().is(tokenPunct, "[") {
+		_, err := p.parseSettings()
+		if err != nil {
+			return err
+		}
+	}
+	r := ref{name: name, child: left, parent: right, line: line}
+	if op == "<" {
+		r.child, r.parent = right, left
+	}
+	if c := p.peek(); c.kind == tokenComment && strings.HasPrefix(c.value, "virtual") {
+		r.virtual = true
+		r.def = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(c.value, "virtual"), ":"))
+	}
+	p.refs = append(p.refs, r)
+	return p.endOfLine()
+}
+
+func (p *parser) parseOperator() (string, error) {
+	t := p.next()
+	switch {
+	case t.is(tokenPunct, "<") && p.peek().is(tokenPunct, ">"):
+		p.next()
+		return "<>", nil
+	case t.is(tokenPunct, "<"), t.is(tokenPunct, ">"), t.is(tokenPunct, "-"):
+		return t.value, nil
+	}
+	return "", p.errorf(t, "unexpected %s, expecting relationship '<', '>', '-' or '<>'", t)
+}
+
+// parseEndpoint parse `table.column`, `schema.table.column` or `table.(column1, column2)`
+func (p *parser) parseEndpoint() (endpoint, error) {
+	parts := []string{}
+	e := endpoint{}
+	for {
+		t := p.next()
+		if t.is(tokenPunct, "(") && len(parts) > 0 {
+			for {
+				c := p.next()
+				if c.is(tokenPunct, ")") {
+					break
+				}
+				if c.is(tokenPunct, ",") {
+					continue
+				}
+				if !c.isName() {
+					return e, p.errorf(c, "unexpected %s, expecting column", c)
+				}
+				e.columns = append(e.columns, c.value)
+			}
+			e.table = strings.Join(parts, ".")
+			return e, nil
+		}
+		if !t.isName() {
+			return e, p.errorf(t, "unexpected %s, expecting table.column", t)
+		}
+		parts = append(parts, t.value)
+		if !p.peek().is(tokenPunct, ".") {
+			break
+		}
+		p.next()
+	}
+	if len(parts) < 2 {
+		return e, p.errorf(p.tokens[p.pos-1], "'%s' is not table.column", strings.Join(parts, "."))
+	}
+	e.table = strings.Join(parts[:len(parts)-1], ".")
+	e.columns = []string{parts[len(parts)-1]}
+	return e, nil
+}
+
+// inlineRef parse column setting `ref: > table.column`
+func (p *parser) inlineRef(table, column string, s setting) (ref, error) {
+	sub := &parser{tokens: append(append([]token{}, s.values...), token{kind: tokenEOF, line: s.line})}
+	op, err := sub.parseOperator()
+	if err != nil {
+		return ref{}, err
+	}
+	other, err := sub.parseEndpoint()
+	if err != nil {
+		return ref{}, err
+	}
+	self := endpoint{table: table, columns: []string{column}}
+	r := ref{child: self, parent: other, line: s.line}
+	if op == "<" {
+		r.child, r.parent = other, self
+	}
+	return r, nil
+}
+
+func addKey(table *schema.Table, typ, name string, columns []string) {
+	suffix := "key"
+	if typ == "PRIMARY KEY" {
+		suffix = "pkey"
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s_%s_%s", table.Name, strings.Join(columns, "_"), suffix)
+		if typ == "PRIMARY KEY" {
+			name = fmt.Sprintf("%s_%s", table.Name, suffix)
+		}
+	}
+	for _, c := range table.Constraints {
+		if c.Type == typ && c.Type == "PRIMARY KEY" {
+			c.Columns = append(c.Columns, columns...)
+			c.Def = fmt.Sprintf("%s (%s)", typ, strings.Join(c.Columns, ", "))
+			for _, i := range table.Indexes {
+				if i.Name == c.Name {
+					i.Columns = c.Columns
+					i.Def = c.Def
+				}
+			}
+			return
+		}
+	}
+	def := fmt.Sprintf("%s (%s)", typ, strings.Join(columns, ", "))
+	table.Constraints = append(table.Constraints, &schema.Constraint{
+		Name:    name,
+		Type:    typ,
+		Def:     def,
+		Table:   &table.Name,
+		Columns: columns,
+	})
+	addIndex(table, name, def, columns)
+}
+
+func addIndex(table *schema.Table, name, def string, columns []string) {
+	table.Indexes = append(table.Indexes, &schema.Index{
+		Name:    indexName(table, name, columns),
+		Def:     def,
+		Table:   &table.Name,
+		Columns: columns,
+	})
+}
+
+func indexName(table *schema.Table, name string, columns []string) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s_%s_idx", table.Name, strings.Join(columns, "_"))
+}
+
+// defaultValue return column default as database returns
+func defaultValue(values []token) string {
+	if len(values) == 0 {
+		return ""
+	}
+	v := values[0]
+	switch v.kind {
+	case tokenString:
+		return fmt.Sprintf("'%s'", strings.Replace(v.value, "'", "''", -1))
+	case tokenExpr:
+		return v.value
+	}
+	vs := []string{}
+	for _, v := range values {
+		vs = append(vs, v.value)
+	}
+	return strings.Join(vs, "")
+}
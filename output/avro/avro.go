@@ -0,0 +1,223 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/typemap"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// Avro struct
+type Avro struct {
+	config *config.Config
+	driver *schema.Driver
+}
+
+// NewAvro return Avro.
+// driver is used to map column types of the database.
+func NewAvro(c *config.Config, d *schema.Driver) *Avro {
+	return &Avro{
+		config: c,
+		driver: d,
+	}
+}
+
+// OutputSchema output Avro schema ( array of record schemas ) for all tables except external tables.
+func (a *Avro) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	driver := a.driver
+	if s.Driver != nil {
+		driver = s.Driver
+	}
+	records := []object{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		records = append(records, table(driverName(driver), t))
+	}
+	return write(wr, records)
+}
+
+// OutputTable output Avro record schema of table.
+func (a *Avro) OutputTable(wr io.Writer, t *schema.Table) error {
+	return write(wr, table(driverName(a.driver), t))
+}
+
+func table(driver string, t *schema.Table) object {
+	namespace := ""
+	name := t.Name
+	if i := strings.LastIndex(t.Name, "."); i > 0 {
+		namespace = t.Name[:i]
+		name = t.Name[i+1:]
+	}
+	o := object{
+		{"type", "record"},
+		{"name", identifier(name)},
+	}
+	if namespace != "" {
+		o = append(o, member{"namespace", namespaceName(namespace)})
+	}
+	if t.Comment != "" {
+		o = append(o, member{"doc", t.Comment})
+	}
+	return append(o, member{"fields", fields(identifier(name), typemap.Fields(driver, t))})
+}
+
+func fields(parent string, fs []*typemap.Field) []object {
+	fields := []object{}
+	for _, f := range fs {
+		name := identifier(f.Name)
+		typ := avroType(fmt.Sprintf("%s_%s", parent, name), f)
+		if f.Type.Repeated {
+			typ = object{
+				{"type", "array"},
+				{"items", typ},
+			}
+		}
+		o := object{{"name", name}}
+		if f.Nullable && !f.Type.Repeated {
+			o = append(o, member{"type", []interface{}{"null", typ}}, member{"default", nil})
+		} else {
+			o = append(o, member{"type", typ})
+		}
+		if f.Column.Comment != "" {
+			o = append(o, member{"doc", f.Column.Comment})
+		}
+		fields = append(fields, o)
+	}
+	return fields
+}
+
+// avroType return Avro type ( primitive type name or complex type ) of the field.
+// name is used for named types ( enum and nested record ).
+func avroType(name string, f *typemap.Field) interface{} {
+	switch f.Type.Kind {
+	case typemap.Boolean:
+		return "boolean"
+	case typemap.Int32:
+		return "int"
+	case typemap.Int64:
+		return "long"
+	case typemap.Float:
+		return "float"
+	case typemap.Double:
+		return "double"
+	case typemap.Decimal:
+		return object{
+			{"type", "bytes"},
+			{"logicalType", "decimal"},
+			{"precision", f.Type.Precision},
+			{"scale", f.Type.Scale},
+		}
+	case typemap.Bytes:
+		return "bytes"
+	case typemap.Date:
+		return object{{"type", "int"}, {"logicalType", "date"}}
+	case typemap.Time:
+		return object{{"type", "long"}, {"logicalType", "time-micros"}}
+	case typemap.Timestamp:
+		return object{{"type", "long"}, {"logicalType", "timestamp-micros"}}
+	case typemap.UUID:
+		return object{{"type", "string"}, {"logicalType", "uuid"}}
+	case typemap.Enum:
+		for _, s := range f.Type.Symbols {
+			if !nameRe.MatchString(s) {
+				return "string"
+			}
+		}
+		return object{
+			{"type", "enum"},
+			{"name", name},
+			{"symbols", f.Type.Symbols},
+		}
+	case typemap.Record:
+		return object{
+			{"type", "record"},
+			{"name", name},
+			{"fields", fields(name, f.Fields)},
+		}
+	}
+	return "string"
+}
+
+var (
+	nameRe         = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	invalidNameRe  = regexp.MustCompile(`[^A-Za-z0-9_]`)
+	invalidSpaceRe = regexp.MustCompile(`[^A-Za-z0-9_.]`)
+)
+
+// identifier return Avro name. Invalid characters are replaced with `_`.
+func identifier(v string) string {
+	v = invalidNameRe.ReplaceAllString(v, "_")
+	if v == "" || (v[0] >= '0' && v[0] <= '9') {
+		v = fmt.Sprintf("_%s", v)
+	}
+	return v
+}
+
+func namespaceName(v string) string {
+	parts := strings.Split(invalidSpaceRe.ReplaceAllString(v, "_"), ".")
+	for i, p := range parts {
+		parts[i] = identifier(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+func driverName(d *schema.Driver) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
+
+func write(wr io.Writer, v interface{}) error {
+	enc := json.NewEncoder(wr)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(v)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+// object is JSON object that keeps the order of members
+type object []member
+
+// MarshalJSON return JSON object keeping the order of members
+func (o object) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("{")
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		err := enc.Encode(m.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteString(":")
+		err = enc.Encode(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
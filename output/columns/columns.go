@@ -0,0 +1,266 @@
+package columns
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// Built-in columns
+const (
+	Name     = "Name"
+	Type     = "Type"
+	Default  = "Default"
+	Nullable = "Nullable"
+	Mode     = "Mode"
+	Children = "Children"
+	Parents  = "Parents"
+	Comment  = "Comment"
+	Labels   = "Labels"
+)
+
+var builtins = []string{Name, Type, Default, Nullable, Mode, Children, Parents, Comment, Labels}
+
+// defaultColumns is default columns per driver
+var defaultColumns = map[string][]string{
+	"bigquery": []string{Name, Type, Default, Mode, Children, Parents, Comment, Labels},
+	"":         []string{Name, Type, Default, Nullable, Children, Parents, Comment, Labels},
+}
+
+// optionalColumns is default columns that are hidden when empty even if hideEmpty is false
+var optionalColumns = map[string]bool{
+	Labels: true,
+}
+
+// defaultHideEmpty is the drivers that hide empty columns by default
+var defaultHideEmpty = map[string]bool{
+	"bigquery": true,
+}
+
+// Column is the column of "Columns" table in table document
+type Column struct {
+	Header   string
+	builtin  string
+	value    *template.Template
+	optional bool
+}
+
+// Format is the format of cell values rendered by Rows
+type Format struct {
+	// Link render related table ( Children, Parents )
+	Link func(t *schema.Table) string
+	// Label render label ( Labels )
+	Label func(l *schema.Label) string
+	// Sep join related tables and labels
+	Sep string
+}
+
+// Columns return the columns of "Columns" table.
+// Columns are `format.columns:` of config, or the default columns of the driver.
+func Columns(c *config.Config, d *schema.Driver) ([]*Column, error) {
+	formats := []config.ColumnFormat{}
+	if c != nil {
+		formats = c.Format.Columns
+	}
+	isDefault := len(formats) == 0
+	if isDefault {
+		names, ok := defaultColumns[driverName(d)]
+		if !ok {
+			names = defaultColumns[""]
+		}
+		for _, n := range names {
+			formats = append(formats, config.ColumnFormat{Name: n})
+		}
+	}
+
+	columns := []*Column{}
+	for _, f := range formats {
+		col := &Column{
+			Header: f.Header,
+		}
+		switch {
+		case f.Value != "":
+			if f.Header == "" {
+				return nil, errors.New(fmt.Sprintf("failed to load column format: header of value '%s' is required", f.Value))
+			}
+			tmpl, err := template.New(f.Header).Parse(f.Value)
+			if err != nil {
+				return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to load column format '%s'", f.Header))
+			}
+			col.value = tmpl
+		default:
+			for _, b := range builtins {
+				if strings.EqualFold(b, f.Name) {
+					col.builtin = b
+				}
+			}
+			if col.builtin == "" {
+				return nil, errors.New(fmt.Sprintf("failed to load column format: unsupported column '%s'", f.Name))
+			}
+			if col.Header == "" {
+				col.Header = col.builtin
+			}
+			col.optional = isDefault && optionalColumns[col.builtin]
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// Builtin return the name of the built-in column, or empty string if the column is a custom column
+func (c *Column) Builtin() string {
+	return c.builtin
+}
+
+// HideEmpty return true if empty columns should be hidden.
+// It is `format.hideEmptyColumns:` of config, or the default of the driver.
+func HideEmpty(c *config.Config, d *schema.Driver) bool {
+	if c != nil && c.Format.HideEmptyColumns != nil {
+		return *c.Format.HideEmptyColumns
+	}
+	return defaultHideEmpty[driverName(d)]
+}
+
+// Table is "Columns" table rendered by Rows
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Rows render "Columns" table of the table.
+// Related tables ( Children, Parents ) and labels are rendered by f.
+// If hideEmpty is true, columns that have no value in all rows are removed ( except Name ).
+// Optional default columns ( Labels ) are always removed when empty.
+func Rows(columns []*Column, t *schema.Table, hideEmpty bool, f Format) (*Table, error) {
+	rows := [][]string{}
+	for _, c := range t.Columns {
+		row := []string{}
+		for _, col := range columns {
+			v, err := col.render(t, c, f)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+		}
+		rows = append(rows, row)
+	}
+
+	header := []string{}
+	keep := []int{}
+	for i, col := range columns {
+		if (hideEmpty || col.optional) && col.builtin != Name && len(rows) > 0 {
+			empty := true
+			for _, r := range rows {
+				if r[i] != "" {
+					empty = false
+					break
+				}
+			}
+			if empty {
+				continue
+			}
+		}
+		keep = append(keep, i)
+		header = append(header, col.Header)
+	}
+	for j, r := range rows {
+		kept := []string{}
+		for _, i := range keep {
+			kept = append(kept, r[i])
+		}
+		rows[j] = kept
+	}
+	return &Table{
+		Header: header,
+		Rows:   rows,
+	}, nil
+}
+
+func (col *Column) render(t *schema.Table, c *schema.Column, f Format) (string, error) {
+	if col.value != nil {
+		buf := new(bytes.Buffer)
+		err := col.value.Execute(buf, map[string]interface{}{
+			"Table":  t,
+			"Column": c,
+		})
+		if err != nil {
+			return "", errors.Wrap(errors.WithStack(err), fmt.Sprintf("failed to render column '%s'", col.Header))
+		}
+		return buf.String(), nil
+	}
+	switch col.builtin {
+	case Name:
+		return c.Name, nil
+	case Type:
+		return c.Type, nil
+	case Default:
+		return c.Default.String, nil
+	case Nullable:
+		return fmt.Sprintf("%v", c.Nullable), nil
+	case Mode:
+		return mode(c), nil
+	case Children:
+		tables := []string{}
+		encountered := map[string]bool{}
+		for _, r := range c.ChildRelations {
+			if encountered[r.Table.Name] {
+				continue
+			}
+			encountered[r.Table.Name] = true
+			tables = append(tables, f.link(r.Table))
+		}
+		return strings.Join(tables, f.Sep), nil
+	case Parents:
+		tables := []string{}
+		encountered := map[string]bool{}
+		for _, r := range c.ParentRelations {
+			if encountered[r.ParentTable.Name] {
+				continue
+			}
+			encountered[r.ParentTable.Name] = true
+			tables = append(tables, f.link(r.ParentTable))
+		}
+		return strings.Join(tables, f.Sep), nil
+	case Comment:
+		return c.Comment, nil
+	case Labels:
+		labels := []string{}
+		for _, l := range c.Labels {
+			labels = append(labels, f.Label(l))
+		}
+		return strings.Join(labels, f.Sep), nil
+	}
+	return "", nil
+}
+
+// mode return BigQuery style column mode ( REQUIRED, NULLABLE or REPEATED )
+func mode(c *schema.Column) string {
+	typ := strings.ToUpper(strings.TrimSpace(c.Type))
+	switch {
+	case c.Repeated || strings.HasPrefix(typ, "ARRAY<") || strings.HasSuffix(typ, "[]"):
+		return "REPEATED"
+	case c.Nullable:
+		return "NULLABLE"
+	}
+	return "REQUIRED"
+}
+
+func driverName(d *schema.Driver) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
+
+// link return the link to the table document. External tables have no document.
+func (f Format) link(t *schema.Table) string {
+	if t.External {
+		return t.Name
+	}
+	return f.Link(t)
+}
@@ -0,0 +1,219 @@
+package columns
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		driver  string
+		formats []config.ColumnFormat
+		want    []string
+	}{
+		{"mysql", nil, []string{"Name", "Type", "Default", "Nullable", "Children", "Parents", "Comment", "Labels"}},
+		{"bigquery", nil, []string{"Name", "Type", "Default", "Mode", "Children", "Parents", "Comment", "Labels"}},
+		{"bigquery", []config.ColumnFormat{{Name: "name"}, {Name: "Comment", Header: "Description"}, {Header: "Example", Value: "{{ .Column.Name }}"}}, []string{"Name", "Description", "Example"}},
+	}
+	for _, tt := range tests {
+		c, err := config.NewConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Format.Columns = tt.formats
+		cols, err := Columns(c, &schema.Driver{Name: tt.driver})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, col := range cols {
+			got = append(got, col.Header)
+		}
+		if fmt.Sprintf("%v", got) != fmt.Sprintf("%v", tt.want) {
+			t.Errorf("got %v\nwant %v", got, tt.want)
+		}
+	}
+}
+
+func TestColumnsError(t *testing.T) {
+	tests := [][]config.ColumnFormat{
+		{{Name: "Unknown"}},
+		{{Value: "{{ .Column.Name }}"}},
+		{{Header: "Broken", Value: "{{ .Column.Name "}},
+	}
+	for _, tt := range tests {
+		c, err := config.NewConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Format.Columns = tt
+		_, err = Columns(c, nil)
+		if err == nil {
+			t.Errorf("%v: got nil\nwant error", tt)
+		}
+	}
+}
+
+func TestRows(t *testing.T) {
+	tests := []struct {
+		hideEmpty  bool
+		wantHeader string
+		wantRows   string
+	}{
+		{false, "[Name Type Default Mode Parents Comment Example]", "[[id INT64  REQUIRED   id] [user_id INT64  NULLABLE [dataset.users] user id user_id] [tags STRING  REPEATED   tags]]"},
+		{true, "[Name Type Mode Parents Comment Example]", "[[id INT64 REQUIRED   id] [user_id INT64 NULLABLE [dataset.users] user id user_id] [tags STRING REPEATED   tags]]"},
+	}
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Format.Columns = []config.ColumnFormat{
+		{Name: "Name"}, {Name: "Type"}, {Name: "Default"}, {Name: "Mode"}, {Name: "Parents"}, {Name: "Comment"},
+		{Header: "Example", Value: "{{ .Column.Name }}"},
+	}
+	cols, err := Columns(c, s.Driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		got, err := Rows(cols, s.Tables[0], tt.hideEmpty, Format{
+			Link: func(t *schema.Table) string {
+				return fmt.Sprintf("[%s]", t.Name)
+			},
+			Label: func(l *schema.Label) string {
+				return l.String()
+			},
+			Sep: " ",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprintf("%v", got.Header) != tt.wantHeader {
+			t.Errorf("got %v\nwant %v", got.Header, tt.wantHeader)
+		}
+		if fmt.Sprintf("%v", got.Rows) != tt.wantRows {
+			t.Errorf("got %v\nwant %v", got.Rows, tt.wantRows)
+		}
+	}
+}
+
+func TestRowsLabels(t *testing.T) {
+	s := newTestSchema()
+	cols, err := Columns(nil, &schema.Driver{Name: "mysql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := Format{
+		Link: func(t *schema.Table) string {
+			return t.Name
+		},
+		Label: func(l *schema.Label) string {
+			return fmt.Sprintf("`%s`", l)
+		},
+		Sep: " ",
+	}
+	got, err := Rows(cols, s.Tables[0], false, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[Name Type Default Nullable Children Parents Comment]"; fmt.Sprintf("%v", got.Header) != want {
+		t.Errorf("got %v\nwant %v", got.Header, want)
+	}
+
+	s.Tables[0].Columns[1].Labels = schema.Labels{{Name: "pii"}, {Name: "retention", Value: "90d"}}
+	got, err = Rows(cols, s.Tables[0], false, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[Name Type Default Nullable Children Parents Comment Labels]"; fmt.Sprintf("%v", got.Header) != want {
+		t.Errorf("got %v\nwant %v", got.Header, want)
+	}
+	if want := "`pii` `retention: 90d`"; got.Rows[1][7] != want {
+		t.Errorf("got %v\nwant %v", got.Rows[1][7], want)
+	}
+}
+
+func TestRowsExternal(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[1].External = true
+	cols, err := Columns(nil, s.Driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Rows(cols, s.Tables[0], true, Format{
+		Link: func(t *schema.Table) string {
+			return fmt.Sprintf("[%s]", t.Name)
+		},
+		Label: func(l *schema.Label) string {
+			return l.String()
+		},
+		Sep: " ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[[id INT64 REQUIRED  ] [user_id INT64 NULLABLE dataset.users user id] [tags STRING REPEATED  ]]"; fmt.Sprintf("%v", got.Rows) != want {
+		t.Errorf("got %v\nwant %v", got.Rows, want)
+	}
+}
+
+func TestHideEmpty(t *testing.T) {
+	hide := false
+	tests := []struct {
+		driver string
+		hide   *bool
+		want   bool
+	}{
+		{"mysql", nil, false},
+		{"bigquery", nil, true},
+		{"bigquery", &hide, false},
+	}
+	for _, tt := range tests {
+		c, err := config.NewConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Format.HideEmptyColumns = tt.hide
+		got := HideEmpty(c, &schema.Driver{Name: tt.driver})
+		if got != tt.want {
+			t.Errorf("%s: got %v\nwant %v", tt.driver, got, tt.want)
+		}
+	}
+}
+
+func newTestSchema() *schema.Schema {
+	users := &schema.Table{
+		Name: "dataset.users",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "INT64"},
+		},
+	}
+	events := &schema.Table{
+		Name: "dataset.events",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "INT64", Default: sql.NullString{}},
+			&schema.Column{Name: "user_id", Type: "INT64", Nullable: true, Comment: "user id"},
+			&schema.Column{Name: "tags", Type: "STRING", Nullable: true, Repeated: true},
+		},
+	}
+	r := &schema.Relation{
+		Table:         events,
+		Columns:       []*schema.Column{events.Columns[1]},
+		ParentTable:   users,
+		ParentColumns: []*schema.Column{users.Columns[0]},
+		Virtual:       true,
+	}
+	events.Columns[1].ParentRelations = []*schema.Relation{r, r}
+	users.Columns[0].ChildRelations = []*schema.Relation{r}
+	return &schema.Schema{
+		Name:      "dataset",
+		Tables:    []*schema.Table{events, users},
+		Relations: []*schema.Relation{r},
+		Driver:    &schema.Driver{Name: "bigquery"},
+	}
+}
@@ -68,7 +68,7 @@ func (c *Config) OutputSchema(wr io.Writer, s *schema.Schema) error {
 					}
 				}
 			} else {
-				a.TableComment = noColumnComment
+				a.TableComment = noTableComment
 			}
 
 			for _, column := range table.Columns {
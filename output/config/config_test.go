@@ -33,6 +33,81 @@ func TestOutputSchema(t *testing.T) {
 	}
 }
 
+func TestUpdateComments(t *testing.T) {
+	tests := []struct {
+		in   string
+		lint config.Lint
+		want string
+	}{
+		{
+			"config_update_test.yml",
+			config.Lint{},
+			"config_update_test.yml.golden",
+		},
+		{
+			"dsn: pg://localhost/testdb\n",
+			config.Lint{RequireTableComment: config.RequireTableComment{Enabled: true, Exclude: []string{"c"}}},
+			"dsn: pg://localhost/testdb\ncomments:\n  - table: a\n    columnComments:\n      a2: column comment required.\n  - table: b\n    tableComment: table comment required.\n    columnComments:\n      b: column comment required.\n  - table: c\n    columnComments:\n      c: column comment required.\n",
+		},
+		{
+			"comments:\n  - columnComments: {}\n    table: c\n",
+			config.Lint{RequireTableComment: config.RequireTableComment{Enabled: true, Exclude: []string{"a", "b", "c"}}},
+			"comments:\n  - columnComments:\n      c: column comment required.\n    table: c\n  - table: a\n    columnComments:\n      a2: column comment required.\n  - table: b\n    columnComments:\n      b: column comment required.\n",
+		},
+		{
+			"dsn: pg://localhost/testdb\r\ncomments:\r\n  - table: c\r\n    tableComment: table c\r\n",
+			config.Lint{RequireTableComment: config.RequireTableComment{Enabled: true, Exclude: []string{"a", "b"}}},
+			"dsn: pg://localhost/testdb\r\ncomments:\r\n  - table: c\r\n    tableComment: table c\r\n    columnComments:\r\n      c: column comment required.\r\n  - table: a\r\n    columnComments:\r\n      a2: column comment required.\r\n  - table: b\r\n    columnComments:\r\n      b: column comment required.\r\n",
+		},
+	}
+	for _, tt := range tests {
+		in, want := []byte(tt.in), []byte(tt.want)
+		if filepath.Ext(tt.in) == ".yml" {
+			var err error
+			in, err = ioutil.ReadFile(filepath.Join(testdataDir(), tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err = ioutil.ReadFile(filepath.Join(testdataDir(), tt.want))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		c, err := config.NewConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Lint = tt.lint
+		o := NewConfig(c)
+		got, err := o.UpdateComments(in, newUpdateTestSchema())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+		// stubs are not added twice
+		again, err := o.UpdateComments(got, newUpdateTestSchema())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(again) != string(got) {
+			t.Errorf("got\n%s\nwant\n%s", again, got)
+		}
+	}
+}
+
+func TestUpdateCommentsFlowStyle(t *testing.T) {
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewConfig(c).UpdateComments([]byte("comments:\n  - {table: b}\n"), newUpdateTestSchema())
+	if err == nil {
+		t.Error("got nil\nwant error")
+	}
+}
+
 func testdataDir() string {
 	wd, _ := os.Getwd()
 	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
@@ -92,3 +167,15 @@ func newTestSchema() *schema.Schema {
 	}
 	return s
 }
+
+func newUpdateTestSchema() *schema.Schema {
+	s := newTestSchema()
+	s.Tables[0].Columns[1].Comment = ""
+	s.Tables[1].Comment = ""
+	s.Tables[1].Columns[0].Comment = ""
+	s.Tables = append(s.Tables, &schema.Table{
+		Name:    "c",
+		Columns: []*schema.Column{&schema.Column{Name: "c"}},
+	})
+	return s
+}
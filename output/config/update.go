@@ -0,0 +1,479 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+var yamlKeyRe = regexp.MustCompile(`^(\s*)([^\s#'"{\[\-][^:#]*?|"[^"]*"|'[^']*')\s*:(?:\s+(.*))?$`)
+var yamlCommentRe = regexp.MustCompile(`(^|\s+)#.*$`)
+
+// commentStub is the missing comments of the table
+type commentStub struct {
+	table        string
+	tableComment bool
+	columns      []string
+}
+
+// UpdateComments return the config file that only missing comment stubs are added to `comments:`.
+// Comments, ordering and formatting of the config file are preserved.
+func (c *Config) UpdateComments(buf []byte, s *schema.Schema) ([]byte, error) {
+	stubs := c.missingComments(s)
+	if len(stubs) == 0 {
+		return buf, nil
+	}
+	u := newUpdater(buf)
+	err := u.update(stubs)
+	if err != nil {
+		return nil, err
+	}
+	updated := []byte(u.String())
+	err = verifyUpdate(buf, updated, stubs)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// missingComments return stubs of tables and columns without comment.
+// If the lint rule is enabled, only the tables and columns that the rule warns are returned.
+func (c *Config) missingComments(s *schema.Schema) []commentStub {
+	tableRule := c.config.Lint.RequireTableComment
+	columnRule := c.config.Lint.RequireColumnComment
+	tableWarns := map[string]bool{}
+	for _, w := range tableRule.Check(s, []string{}) {
+		tableWarns[w.Target] = true
+	}
+	columnWarns := map[string]bool{}
+	for _, w := range columnRule.Check(s, []string{}) {
+		columnWarns[w.Target] = true
+	}
+
+	stubs := []commentStub{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		stub := commentStub{table: t.Name}
+		if t.Comment == "" && (!tableRule.IsEnabled() || tableWarns[t.Name]) {
+			stub.tableComment = true
+		}
+		for _, col := range t.Columns {
+			if col.Comment == "" && (!columnRule.IsEnabled() || columnWarns[fmt.Sprintf("%s.%s", t.Name, col.Name)]) {
+				stub.columns = append(stub.columns, col.Name)
+			}
+		}
+		if stub.tableComment || len(stub.columns) > 0 {
+			stubs = append(stubs, stub)
+		}
+	}
+	return stubs
+}
+
+// updater insert lines into the config file without touching existing lines
+type updater struct {
+	lines   []string
+	inserts map[int][]string // lines inserted after the line
+	newline string
+}
+
+// newUpdater return updater of the config file. The line ending ( LF or CRLF ) of the file is kept.
+func newUpdater(buf []byte) *updater {
+	u := &updater{lines: strings.Split(string(buf), "\n"), inserts: map[int][]string{}, newline: "\n"}
+	if strings.Contains(string(buf), "\r\n") {
+		u.newline = "\r\n"
+		for i, l := range u.lines {
+			u.lines[i] = strings.TrimSuffix(l, "\r")
+		}
+	}
+	return u
+}
+
+// commentItem is the position of an item of `comments:`
+type commentItem struct {
+	table       string
+	dash        int
+	indent      int
+	last        int
+	keys        map[string]int
+	columns     map[string]bool
+	columnsLast int
+	columnsInd  int
+	columnsFlow string
+}
+
+func (u *updater) update(stubs []commentStub) error {
+	key, ok := u.findKey(0, len(u.lines), 0, "comments")
+	if !ok {
+		last := u.lastContent(0, len(u.lines))
+		lines := []string{"comments:"}
+		for _, stub := range stubs {
+			lines = append(lines, newCommentItem(stub, 2, 4, false)...)
+		}
+		u.insert(last, lines...)
+		return nil
+	}
+	value := inlineValue(u.lines[key])
+	switch value {
+	case "":
+	case "[]", "null", "~":
+		u.lines[key] = dropInlineValue(u.lines[key], value)
+	default:
+		return errors.New(fmt.Sprintf("failed to update line %d: flow style `comments:` is not supported", key+1))
+	}
+
+	end := key + 1
+	for ; end < len(u.lines); end++ {
+		l := u.lines[end]
+		if isBlankLine(l) {
+			continue
+		}
+		if indentOf(l) == 0 && !isSeqEntry(l) {
+			break
+		}
+	}
+	items, err := u.commentItems(key+1, end)
+	if err != nil {
+		return err
+	}
+
+	dashInd, keyInd, dashAlone := 2, 4, false
+	if len(items) > 0 {
+		dashInd = indentOf(u.lines[items[0].dash])
+		keyInd = items[0].indent
+		dashAlone = strings.TrimSpace(u.lines[items[0].dash]) == "-"
+	}
+	// new items are added after updating existing items, because they are inserted after the last item
+	added := []string{}
+	for _, stub := range stubs {
+		var item *commentItem
+		for _, i := range items {
+			if i.table == stub.table {
+				item = i
+				break
+			}
+		}
+		if item == nil {
+			added = append(added, newCommentItem(stub, dashInd, keyInd, dashAlone)...)
+			continue
+		}
+		u.updateCommentItem(item, stub)
+	}
+	u.insert(u.lastContent(key, end), added...)
+	return nil
+}
+
+// commentItems return items of `comments:` between the lines
+func (u *updater) commentItems(start, end int) ([]*commentItem, error) {
+	items := []*commentItem{}
+	dashInd := -1
+	for i := start; i < end; i++ {
+		l := u.lines[i]
+		if isBlankLine(l) || !isSeqEntry(l) || (dashInd >= 0 && indentOf(l) != dashInd) {
+			continue
+		}
+		dashInd = indentOf(l)
+		next := end
+		for j := i + 1; j < end; j++ {
+			if !isBlankLine(u.lines[j]) && isSeqEntry(u.lines[j]) && indentOf(u.lines[j]) == dashInd {
+				next = j
+				break
+			}
+		}
+		item, err := u.commentItem(i, next)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		i = next - 1
+	}
+	return items, nil
+}
+
+func (u *updater) commentItem(start, end int) (*commentItem, error) {
+	item := &commentItem{
+		dash:    start,
+		keys:    map[string]int{},
+		columns: map[string]bool{},
+		last:    u.lastContent(start, end),
+	}
+	dash := u.lines[start]
+	rest := strings.TrimLeft(dash[indentOf(dash)+1:], " ")
+	if strings.HasPrefix(rest, "{") {
+		return nil, errors.New(fmt.Sprintf("failed to update line %d: flow style item of `comments:` is not supported", start+1))
+	}
+	// key lines of the item ( the first key may be on the line of `-` )
+	keyLines := map[int]string{}
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		item.indent = len(dash) - len(rest)
+		keyLines[start] = strings.Repeat(" ", item.indent) + rest
+	} else {
+		item.indent = -1
+	}
+	for i := start + 1; i < end; i++ {
+		l := u.lines[i]
+		if isBlankLine(l) {
+			continue
+		}
+		if item.indent < 0 {
+			item.indent = indentOf(l)
+		}
+		if indentOf(l) == item.indent {
+			keyLines[i] = l
+		}
+	}
+	for i, l := range keyLines {
+		m := yamlKeyRe.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		k := strings.Trim(m[2], `"'`)
+		item.keys[k] = i
+		if k == "table" {
+			v := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(strings.TrimSpace(l)), &v); err == nil {
+				item.table = fmt.Sprintf("%v", v["table"])
+			}
+		}
+	}
+
+	item.columnsInd = item.indent + 2
+	cc, ok := item.keys["columnComments"]
+	if !ok {
+		return item, nil
+	}
+	// keyLines has the line of `-` without the dash
+	switch v := inlineValue(keyLines[cc]); v {
+	case "":
+	case "{}", "null", "~":
+		item.columnsFlow = v
+	default:
+		return nil, errors.New(fmt.Sprintf("failed to update line %d: flow style `columnComments:` is not supported", cc+1))
+	}
+	item.columnsLast = cc
+	columnsInd := -1
+	for i := cc + 1; i < end; i++ {
+		l := u.lines[i]
+		if isBlankLine(l) {
+			continue
+		}
+		if indentOf(l) <= item.indent {
+			break
+		}
+		item.columnsLast = i
+		if columnsInd < 0 {
+			columnsInd = indentOf(l)
+			item.columnsInd = columnsInd
+		}
+		if indentOf(l) != columnsInd {
+			continue
+		}
+		if m := yamlKeyRe.FindStringSubmatch(l); m != nil {
+			item.columns[strings.Trim(m[2], `"'`)] = true
+		}
+	}
+	return item, nil
+}
+
+func (u *updater) updateCommentItem(item *commentItem, stub commentStub) {
+	if _, ok := item.keys["tableComment"]; !ok && stub.tableComment {
+		after := item.last
+		if t, ok := item.keys["table"]; ok {
+			after = t
+		}
+		u.insert(after, yamlKeyValue(item.indent, "tableComment", noTableComment))
+	}
+	columns := []string{}
+	for _, c := range stub.columns {
+		if !item.columns[c] {
+			columns = append(columns, yamlKeyValue(item.columnsInd, c, noColumnComment))
+		}
+	}
+	if len(columns) == 0 {
+		return
+	}
+	cc, ok := item.keys["columnComments"]
+	if !ok {
+		columns = append([]string{strings.Repeat(" ", item.indent) + "columnComments:"}, columns...)
+		u.insert(item.last, columns...)
+		return
+	}
+	if item.columnsFlow != "" {
+		u.lines[cc] = dropInlineValue(u.lines[cc], item.columnsFlow)
+	}
+	u.insert(item.columnsLast, columns...)
+}
+
+// findKey return the line of the key in the mapping of the indent
+func (u *updater) findKey(start, end, indent int, key string) (int, bool) {
+	for i := start; i < end; i++ {
+		l := u.lines[i]
+		if isBlankLine(l) || indentOf(l) != indent {
+			continue
+		}
+		m := yamlKeyRe.FindStringSubmatch(l)
+		if m != nil && strings.Trim(m[2], `"'`) == key {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// lastContent return the last line that is not blank or comment
+func (u *updater) lastContent(start, end int) int {
+	last := start - 1
+	for i := start; i < end; i++ {
+		if !isBlankLine(u.lines[i]) {
+			last = i
+		}
+	}
+	return last
+}
+
+func (u *updater) insert(after int, lines ...string) {
+	u.inserts[after] = append(u.inserts[after], lines...)
+}
+
+func (u *updater) String() string {
+	lines := []string{}
+	if l, ok := u.inserts[-1]; ok {
+		lines = append(lines, l...)
+	}
+	for i, l := range u.lines {
+		lines = append(lines, l)
+		lines = append(lines, u.inserts[i]...)
+	}
+	return strings.Join(lines, u.newline)
+}
+
+func newCommentItem(stub commentStub, dashInd, keyInd int, dashAlone bool) []string {
+	table := yamlKeyValue(keyInd, "table", stub.table)
+	lines := []string{}
+	if dashAlone {
+		lines = append(lines, strings.Repeat(" ", dashInd)+"-", table)
+	} else {
+		lines = append(lines, strings.Repeat(" ", dashInd)+"-"+table[dashInd+1:])
+	}
+	if stub.tableComment {
+		lines = append(lines, yamlKeyValue(keyInd, "tableComment", noTableComment))
+	}
+	if len(stub.columns) > 0 {
+		lines = append(lines, strings.Repeat(" ", keyInd)+"columnComments:")
+		for _, c := range stub.columns {
+			lines = append(lines, yamlKeyValue(keyInd+2, c, noColumnComment))
+		}
+	}
+	return lines
+}
+
+// verifyUpdate verify that the updated config file only has stubs added to the original one
+func verifyUpdate(orig, updated []byte, stubs []commentStub) error {
+	type configFile struct {
+		Comments []config.AdditionalComment `yaml:"comments"`
+	}
+	failed := errors.New("failed to update config file in place. the layout of `comments:` is not supported")
+	o, n := yaml.MapSlice{}, yaml.MapSlice{}
+	if err := yaml.Unmarshal(orig, &o); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := yaml.Unmarshal(updated, &n); err != nil {
+		return failed
+	}
+	if !reflect.DeepEqual(withoutKey(o, "comments"), withoutKey(n, "comments")) {
+		return failed
+	}
+	oc, nc := configFile{}, configFile{}
+	if err := yaml.Unmarshal(orig, &oc); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := yaml.Unmarshal(updated, &nc); err != nil || len(nc.Comments) < len(oc.Comments) {
+		return failed
+	}
+	for i, c := range oc.Comments {
+		a := nc.Comments[i]
+		if a.Table != c.Table || (c.TableComment != "" && a.TableComment != c.TableComment) || !reflect.DeepEqual(a.Labels, c.Labels) || !reflect.DeepEqual(a.ColumnLabels, c.ColumnLabels) {
+			return failed
+		}
+		for k, v := range c.ColumnComments {
+			if a.ColumnComments[k] != v {
+				return failed
+			}
+		}
+	}
+	for _, stub := range stubs {
+		found := false
+		for _, c := range nc.Comments {
+			if c.Table != stub.table {
+				continue
+			}
+			found = true
+			for _, col := range stub.columns {
+				if _, ok := c.ColumnComments[col]; !ok {
+					return failed
+				}
+			}
+			break
+		}
+		if !found {
+			return failed
+		}
+	}
+	return nil
+}
+
+func withoutKey(m yaml.MapSlice, key string) yaml.MapSlice {
+	r := yaml.MapSlice{}
+	for _, i := range m {
+		if i.Key != key {
+			r = append(r, i)
+		}
+	}
+	return r
+}
+
+func yamlKeyValue(indent int, key, value string) string {
+	b, _ := yaml.Marshal(yaml.MapSlice{{Key: key, Value: value}})
+	return strings.Repeat(" ", indent) + strings.TrimRight(string(b), "\n")
+}
+
+// inlineValue return the value on the line of the key without comment
+func inlineValue(l string) string {
+	m := yamlKeyRe.FindStringSubmatch(l)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(yamlCommentRe.ReplaceAllString(m[3], ""))
+}
+
+// dropInlineValue return the line of the key that the value is removed from
+func dropInlineValue(l, v string) string {
+	i := strings.Index(l, ":")
+	j := strings.Index(l[i:], v) + i
+	head := strings.TrimRight(l[:j], " ")
+	tail := strings.TrimLeft(l[j+len(v):], " ")
+	if tail == "" {
+		return head
+	}
+	return fmt.Sprintf("%s %s", head, tail)
+}
+
+func indentOf(l string) int {
+	return len(l) - len(strings.TrimLeft(l, " "))
+}
+
+func isBlankLine(l string) bool {
+	t := strings.TrimSpace(l)
+	return t == "" || strings.HasPrefix(t, "#")
+}
+
+func isSeqEntry(l string) bool {
+	t := strings.TrimLeft(l, " ")
+	return t == "-" || strings.HasPrefix(t, "- ") || strings.HasPrefix(t, "-\t")
+}
@@ -0,0 +1,286 @@
+package dbml
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// DBML struct
+type DBML struct {
+	config *config.Config
+}
+
+// NewDBML return DBML
+func NewDBML(c *config.Config) *DBML {
+	return &DBML{
+		config: c,
+	}
+}
+
+// OutputSchema output DBML format for full relation.
+func (d *DBML) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	return d.output(wr, s.Tables, s.Relations)
+}
+
+// OutputTable output DBML format for table.
+func (d *DBML) OutputTable(wr io.Writer, t *schema.Table) error {
+	encountered := map[string]bool{t.Name: true}
+	tables := []*schema.Table{t}
+	relations := []*schema.Relation{}
+	for _, c := range t.Columns {
+		for _, r := range c.ParentRelations {
+			if !encountered[r.ParentTable.Name] {
+				encountered[r.ParentTable.Name] = true
+				tables = append(tables, r.ParentTable)
+			}
+			if !contains(relations, r) {
+				relations = append(relations, r)
+			}
+		}
+		for _, r := range c.ChildRelations {
+			if !encountered[r.Table.Name] {
+				encountered[r.Table.Name] = true
+				tables = append(tables, r.Table)
+			}
+			if !contains(relations, r) {
+				relations = append(relations, r)
+			}
+		}
+	}
+	return d.output(wr, tables, relations)
+}
+
+func (d *DBML) output(wr io.Writer, tables []*schema.Table, relations []*schema.Relation) error {
+	blocks := []string{}
+	groups := []string{}
+	members := map[string][]string{}
+	for _, t := range tables {
+		blocks = append(blocks, table(t))
+		ns, _ := splitName(t.Name)
+		if ns == "" {
+			continue
+		}
+		if _, ok := members[ns]; !ok {
+			groups = append(groups, ns)
+		}
+		members[ns] = append(members[ns], tableName(t.Name))
+	}
+	if len(relations) > 0 {
+		refs := []string{}
+		for _, r := range relations {
+			refs = append(refs, ref(r))
+		}
+		blocks = append(blocks, strings.Join(refs, "\n"))
+	}
+	for _, ns := range groups {
+		lines := []string{fmt.Sprintf("TableGroup %s {", quote(ns))}
+		for _, m := range members[ns] {
+			lines = append(lines, fmt.Sprintf("  %s", m))
+		}
+		lines = append(lines, "}")
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	_, err := fmt.Fprintf(wr, "%s\n", strings.Join(blocks, "\n\n"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// table return Table block. External tables are marked by leading comment because DBML has no notion of them.
+func table(t *schema.Table) string {
+	lines := []string{fmt.Sprintf("Table %s {", tableName(t.Name))}
+	if t.External {
+		lines = append([]string{"// external table"}, lines...)
+	}
+	for _, c := range t.Columns {
+		lines = append(lines, fmt.Sprintf("  %s", column(t, c)))
+	}
+	if t.Comment != "" {
+		lines = append(lines, "", fmt.Sprintf("  Note: %s", note(t.Comment)))
+	}
+	indexes := []string{}
+	for _, i := range t.Indexes {
+		if len(i.Columns) == 0 {
+			continue
+		}
+		indexes = append(indexes, fmt.Sprintf("    %s", index(i)))
+	}
+	if len(indexes) > 0 {
+		lines = append(lines, "", "  indexes {")
+		lines = append(lines, indexes...)
+		lines = append(lines, "  }")
+	}
+	lines = append(lines, "}")
+	return strings.Join(lines, "\n")
+}
+
+func column(t *schema.Table, c *schema.Column) string {
+	settings := []string{}
+	if t.IsPrimaryKey([]string{c.Name}) {
+		settings = append(settings, "pk")
+	} else if t.IsUnique([]string{c.Name}) {
+		settings = append(settings, "unique")
+	}
+	if !c.Nullable {
+		settings = append(settings, "not null")
+	}
+	if c.Default.Valid {
+		settings = append(settings, fmt.Sprintf("default: %s", defaultValue(c.Default.String)))
+	}
+	if c.Comment != "" {
+		settings = append(settings, fmt.Sprintf("note: %s", note(c.Comment)))
+	}
+	typ := c.Type
+	if typ == "" {
+		typ = "unknown"
+	}
+	col := fmt.Sprintf("%s %s", quote(c.Name), columnType(typ))
+	if len(settings) > 0 {
+		col = fmt.Sprintf("%s [%s]", col, strings.Join(settings, ", "))
+	}
+	return col
+}
+
+func index(i *schema.Index) string {
+	columns := []string{}
+	for _, c := range i.Columns {
+		columns = append(columns, quote(c))
+	}
+	idx := columns[0]
+	if len(columns) > 1 {
+		idx = fmt.Sprintf("(%s)", strings.Join(columns, ", "))
+	}
+	settings := []string{}
+	switch {
+	case strings.Contains(i.Def, "PRIMARY"):
+		settings = append(settings, "pk")
+	case strings.Contains(i.Def, "UNIQUE"):
+		settings = append(settings, "unique")
+	}
+	if i.Name != "" {
+		settings = append(settings, fmt.Sprintf("name: %s", note(i.Name)))
+	}
+	if len(settings) > 0 {
+		idx = fmt.Sprintf("%s [%s]", idx, strings.Join(settings, ", "))
+	}
+	return idx
+}
+
+// ref return Ref line. Child side is "one" when the columns are unique, otherwise "many".
+// Virtual relations are marked by trailing comment because DBML has no notion of them.
+func ref(r *schema.Relation) string {
+	columns := []string{}
+	for _, c := range r.Columns {
+		columns = append(columns, c.Name)
+	}
+	parentColumns := []string{}
+	for _, c := range r.ParentColumns {
+		parentColumns = append(parentColumns, c.Name)
+	}
+	op := ">"
+	if r.Table.IsUnique(columns) {
+		op = "-"
+	}
+	line := fmt.Sprintf("Ref: %s %s %s", columnRef(r.Table.Name, columns), op, columnRef(r.ParentTable.Name, parentColumns))
+	if r.Virtual {
+		line = fmt.Sprintf("%s // virtual: %s", line, oneLine(r.Def))
+	}
+	return line
+}
+
+func columnRef(table string, columns []string) string {
+	quoted := []string{}
+	for _, c := range columns {
+		quoted = append(quoted, quote(c))
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf("%s.%s", tableName(table), quoted[0])
+	}
+	return fmt.Sprintf("%s.(%s)", tableName(table), strings.Join(quoted, ", "))
+}
+
+// splitName split table name into namespace ( schema or dataset ) and name
+func splitName(name string) (string, string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+func tableName(name string) string {
+	ns, n := splitName(name)
+	if ns == "" {
+		return quote(n)
+	}
+	return fmt.Sprintf("%s.%s", quote(ns), quote(n))
+}
+
+var (
+	plainRe         = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	plainTypeRe     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\([0-9, ]*\))?(\[\])?$`)
+	numberRe        = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	stringLiteralRe = regexp.MustCompile(`^'(.*)'$`)
+	castedLiteralRe = regexp.MustCompile(`^'(.*)'::[A-Za-z ]+$`)
+	keywordDefault  = map[string]string{"true": "true", "false": "false", "null": "null"}
+)
+
+func quote(v string) string {
+	if plainRe.MatchString(v) {
+		return v
+	}
+	return fmt.Sprintf(`"%s"`, strings.Replace(v, `"`, `\"`, -1))
+}
+
+func columnType(v string) string {
+	if plainTypeRe.MatchString(v) {
+		return v
+	}
+	return fmt.Sprintf(`"%s"`, strings.Replace(v, `"`, `\"`, -1))
+}
+
+// defaultValue return DBML default value. Values that are not literals are treated as expressions.
+func defaultValue(v string) string {
+	if numberRe.MatchString(v) {
+		return v
+	}
+	if k, ok := keywordDefault[strings.ToLower(v)]; ok {
+		return k
+	}
+	if m := castedLiteralRe.FindStringSubmatch(v); m != nil {
+		return fmt.Sprintf("'%s'", m[1])
+	}
+	if stringLiteralRe.MatchString(v) {
+		return v
+	}
+	return fmt.Sprintf("`%s`", strings.Replace(v, "`", "\\`", -1))
+}
+
+func note(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	if strings.ContainsAny(v, "\r\n") {
+		return fmt.Sprintf("'''%s'''", strings.Replace(r.Replace(v), "\r\n", "\n", -1))
+	}
+	return fmt.Sprintf("'%s'", r.Replace(v))
+}
+
+func oneLine(v string) string {
+	r := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+	return r.Replace(v)
+}
+
+func contains(rs []*schema.Relation, e *schema.Relation) bool {
+	for _, r := range rs {
+		if e == r {
+			return true
+		}
+	}
+	return false
+}
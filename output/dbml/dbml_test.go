@@ -0,0 +1,214 @@
+package dbml
+
+import (
+	"bytes"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestOutputSchema(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "out_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewDBML(c)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "dbml_test_schema.dbml.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputTable(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "out_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	ta := s.Tables[0]
+
+	o := NewDBML(c)
+	buf := &bytes.Buffer{}
+	_ = o.OutputTable(buf, ta)
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "dbml_test_a.dbml.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestTableGroup(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[1].Name = "sales.b"
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	buf := &bytes.Buffer{}
+	err = NewDBML(c).OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	for _, want := range []string{
+		`Table sales.b {`,
+		`Ref: a.a > sales.b.b`,
+		"TableGroup sales {\n  sales.b\n}",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("actual %v\nwant %v", buf.String(), want)
+		}
+	}
+}
+
+var defaultValueTests = []struct {
+	in   string
+	want string
+}{
+	{"0", "0"},
+	{"-1.5", "-1.5"},
+	{"TRUE", "true"},
+	{"NULL", "null"},
+	{"'draft'", "'draft'"},
+	{"'draft'::character varying", "'draft'"},
+	{"CURRENT_TIMESTAMP", "`CURRENT_TIMESTAMP`"},
+	{"nextval('users_id_seq'::regclass)", "`nextval('users_id_seq'::regclass)`"},
+}
+
+func TestOutputSchemaExternal(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[0].External = true
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewDBML(c)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := buf.String(), "// external table\nTable a {"; !strings.Contains(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	for _, tt := range defaultValueTests {
+		if got := defaultValue(tt.in); got != tt.want {
+			t.Errorf("%s: got %v\nwant %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
+
+func newTestSchema() *schema.Schema {
+	ca := &schema.Column{
+		Name:     "a",
+		Type:     "bigint",
+		Nullable: true,
+		Comment:  "column a",
+	}
+	cb := &schema.Column{
+		Name:    "b",
+		Type:    "bigint",
+		Comment: "column b",
+	}
+
+	ta := &schema.Table{
+		Name:    "a",
+		Comment: "table a",
+		Columns: []*schema.Column{
+			ca,
+			&schema.Column{
+				Name:    "a2",
+				Type:    "character varying(255)",
+				Default: sql.NullString{String: "'draft'::character varying", Valid: true},
+				Comment: "column a2",
+			},
+		},
+		Indexes: []*schema.Index{
+			&schema.Index{
+				Name:    "a_a_a2_idx",
+				Def:     "CREATE INDEX a_a_a2_idx ON a (a, a2)",
+				Columns: []string{"a", "a2"},
+			},
+		},
+	}
+	tb := &schema.Table{
+		Name:    "b",
+		Comment: "table b",
+		Columns: []*schema.Column{
+			cb,
+			&schema.Column{
+				Name:    "b2",
+				Type:    "text",
+				Comment: "column b2",
+			},
+		},
+		Indexes: []*schema.Index{
+			&schema.Index{
+				Name:    "b_pkey",
+				Def:     "PRIMARY KEY (b)",
+				Columns: []string{"b"},
+			},
+		},
+	}
+	r := &schema.Relation{
+		Table:         ta,
+		Columns:       []*schema.Column{ca},
+		ParentTable:   tb,
+		ParentColumns: []*schema.Column{cb},
+	}
+	ca.ParentRelations = []*schema.Relation{r}
+	cb.ChildRelations = []*schema.Relation{r}
+
+	s := &schema.Schema{
+		Name: "testschema",
+		Tables: []*schema.Table{
+			ta,
+			tb,
+		},
+		Relations: []*schema.Relation{
+			r,
+		},
+		Driver: &schema.Driver{
+			Name:            "testdriver",
+			DatabaseVersion: "1.0.0",
+		},
+	}
+	return s
+}
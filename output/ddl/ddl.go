@@ -0,0 +1,685 @@
+package ddl
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/typemap"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// Dialects
+const (
+	Postgres = "postgres"
+	MySQL    = "mysql"
+	SQLite   = "sqlite"
+	BigQuery = "bigquery"
+)
+
+// Dialects is supported target dialects
+var Dialects = []string{Postgres, MySQL, SQLite, BigQuery}
+
+// Supported return true if DDL of the dialect can be output
+func Supported(dialect string) bool {
+	for _, d := range Dialects {
+		if d == dialect {
+			return true
+		}
+	}
+	return false
+}
+
+// DDL struct
+type DDL struct {
+	config  *config.Config
+	driver  *schema.Driver
+	dialect string
+}
+
+// NewDDL return DDL.
+// When dialect is empty, DDL is output in the dialect of the database driver ( or PostgreSQL ).
+func NewDDL(c *config.Config, d *schema.Driver, dialect string) *DDL {
+	if dialect == "" {
+		dialect = Postgres
+		if d != nil && Supported(d.Name) {
+			dialect = d.Name
+		}
+	}
+	return &DDL{
+		config:  c,
+		driver:  d,
+		dialect: dialect,
+	}
+}
+
+// OutputSchema output DDL for all tables. External tables are not created.
+func (d *DDL) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	driver := d.driver
+	if s.Driver != nil {
+		driver = s.Driver
+	}
+	tables := []*schema.Table{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		tables = append(tables, t)
+	}
+	return d.output(wr, driver, tables, s.Relations)
+}
+
+// OutputTable output DDL for table.
+func (d *DDL) OutputTable(wr io.Writer, t *schema.Table) error {
+	relations := []*schema.Relation{}
+	for _, c := range t.Columns {
+		for _, r := range c.ParentRelations {
+			if !contains(relations, r) {
+				relations = append(relations, r)
+			}
+		}
+	}
+	return d.output(wr, d.driver, []*schema.Table{t}, relations)
+}
+
+func (d *DDL) output(wr io.Writer, driver *schema.Driver, tables []*schema.Table, relations []*schema.Relation) error {
+	if !Supported(d.dialect) {
+		return errors.WithStack(fmt.Errorf("unsupported dialect '%s'", d.dialect))
+	}
+	g := &generator{
+		dialect: d.dialect,
+		source:  "",
+	}
+	if driver != nil {
+		g.source = driver.Name
+	}
+
+	blocks := []string{}
+	if ns := g.createSchemas(tables); ns != "" {
+		blocks = append(blocks, ns)
+	}
+	views := []*schema.Table{}
+	for _, t := range tables {
+		if isView(t) {
+			views = append(views, t)
+			continue
+		}
+		blocks = append(blocks, g.createTable(t, relations))
+	}
+	if fks := g.foreignKeys(tables, relations); fks != "" {
+		blocks = append(blocks, fks)
+	}
+	for _, t := range views {
+		blocks = append(blocks, g.createView(t))
+	}
+
+	_, err := fmt.Fprintf(wr, "%s\n", strings.Join(blocks, "\n\n"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// generator generate statements of the target dialect from the schema of the source driver
+type generator struct {
+	dialect string
+	source  string
+}
+
+// same return true if the source driver and the target dialect are the same, so that database specific definitions can be output verbatim
+func (g *generator) same() bool {
+	return g.source == g.dialect
+}
+
+func (g *generator) createSchemas(tables []*schema.Table) string {
+	if g.dialect == SQLite {
+		return ""
+	}
+	stmts := []string{}
+	encountered := map[string]bool{}
+	for _, t := range tables {
+		ns, _ := splitName(t.Name)
+		if ns == "" || encountered[ns] || (g.dialect == Postgres && ns == "public") {
+			continue
+		}
+		encountered[ns] = true
+		stmts = append(stmts, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", g.ident(ns)))
+	}
+	return strings.Join(stmts, "\n")
+}
+
+func (g *generator) createTable(t *schema.Table, relations []*schema.Relation) string {
+	lines := []string{}
+	fields := typemap.Fields(g.source, t)
+	for _, f := range fields {
+		lines = append(lines, g.comment(f.Column.Comment, "  ")+"  "+g.column(t, f))
+	}
+	if g.dialect != BigQuery {
+		for _, c := range t.Constraints {
+			if def := g.constraint(c); def != "" {
+				lines = append(lines, fmt.Sprintf("  %s", def))
+			}
+		}
+	}
+	if g.dialect == SQLite {
+		for _, r := range relations {
+			if r.Table == t && !r.Virtual {
+				lines = append(lines, fmt.Sprintf("  %s", g.foreignKey(r)))
+			}
+		}
+	}
+
+	stmt := fmt.Sprintf("%sCREATE TABLE %s (\n", g.comment(t.Comment, ""), g.tableName(t.Name))
+	for i, l := range lines {
+		stmt += l
+		if i < len(lines)-1 {
+			stmt += ","
+		}
+		stmt += "\n"
+	}
+	stmt += ")"
+	switch g.dialect {
+	case MySQL:
+		if t.Comment != "" {
+			stmt += fmt.Sprintf(" COMMENT=%s", g.literal(t.Comment))
+		}
+	case BigQuery:
+		if t.Comment != "" {
+			stmt += fmt.Sprintf("\nOPTIONS(description=%s)", g.literal(t.Comment))
+		}
+	}
+	stmts := []string{stmt + ";"}
+
+	if g.dialect != BigQuery {
+		for _, i := range t.Indexes {
+			if idx := g.index(t, i); idx != "" {
+				stmts = append(stmts, idx)
+			}
+		}
+	}
+
+	if g.dialect == Postgres {
+		if t.Comment != "" {
+			stmts = append(stmts, fmt.Sprintf("COMMENT ON TABLE %s IS %s;", g.tableName(t.Name), g.literal(t.Comment)))
+		}
+		for _, f := range fields {
+			if f.Column.Comment != "" {
+				stmts = append(stmts, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", g.tableName(t.Name), g.ident(f.Name), g.literal(f.Column.Comment)))
+			}
+		}
+	}
+	return strings.Join(stmts, "\n")
+}
+
+func (g *generator) column(t *schema.Table, f *typemap.Field) string {
+	c := f.Column
+	typ := g.columnType(f)
+	autoIncrement := isAutoIncrement(g.source, t, c)
+	if autoIncrement && g.dialect == Postgres {
+		switch f.Type.Kind {
+		case typemap.Int64:
+			typ = "bigserial"
+		case typemap.Int32:
+			typ = "serial"
+		}
+	}
+	def := fmt.Sprintf("%s %s", g.ident(f.Name), typ)
+	if !f.Nullable && !(g.dialect == BigQuery && f.Type.Repeated) {
+		def += " NOT NULL"
+	}
+	if autoIncrement && g.dialect == MySQL {
+		def += " AUTO_INCREMENT"
+	}
+	if v := g.defaultValue(f); v != "" && !autoIncrement {
+		def += fmt.Sprintf(" DEFAULT %s", v)
+	}
+	if f.Type.Kind == typemap.Enum && (g.dialect == Postgres || g.dialect == SQLite) && !g.same() {
+		symbols := []string{}
+		for _, s := range f.Type.Symbols {
+			symbols = append(symbols, g.literal(s))
+		}
+		def += fmt.Sprintf(" CHECK (%s IN (%s))", g.ident(f.Name), strings.Join(symbols, ", "))
+	}
+	if c.Comment != "" {
+		switch g.dialect {
+		case MySQL:
+			def += fmt.Sprintf(" COMMENT %s", g.literal(c.Comment))
+		case BigQuery:
+			def += fmt.Sprintf(" OPTIONS(description=%s)", g.literal(c.Comment))
+		}
+	}
+	return def
+}
+
+var lengthRe = regexp.MustCompile(`^(?i)(?:n?varchar|varchar2|character varying|n?char|character)\s*\((\d+)\)`)
+
+// columnType return column type of the target dialect
+func (g *generator) columnType(f *typemap.Field) string {
+	if g.dialect == BigQuery && f.Type.Kind == typemap.Record {
+		fields := []string{}
+		for _, n := range f.Fields {
+			fields = append(fields, fmt.Sprintf("%s %s", g.ident(n.Name), g.columnType(n)))
+		}
+		typ := fmt.Sprintf("STRUCT<%s>", strings.Join(fields, ", "))
+		if f.Type.Repeated {
+			typ = fmt.Sprintf("ARRAY<%s>", typ)
+		}
+		return typ
+	}
+	if g.same() && f.Type.Kind != typemap.Record && f.Column.Type != "" {
+		return f.Column.Type
+	}
+	if f.Type.Kind == typemap.Record {
+		switch g.dialect {
+		case Postgres:
+			return "jsonb"
+		case MySQL:
+			return "json"
+		}
+		return "text"
+	}
+	typ := g.scalar(f.Type, f.Column.Type)
+	if f.Type.Repeated {
+		switch g.dialect {
+		case Postgres:
+			return fmt.Sprintf("%s[]", typ)
+		case MySQL:
+			return "json"
+		case SQLite:
+			return "text"
+		case BigQuery:
+			return fmt.Sprintf("ARRAY<%s>", typ)
+		}
+	}
+	return typ
+}
+
+func (g *generator) scalar(t typemap.Type, source string) string {
+	length := ""
+	if m := lengthRe.FindStringSubmatch(strings.TrimSpace(source)); m != nil {
+		length = m[1]
+	}
+	switch g.dialect {
+	case Postgres:
+		switch t.Kind {
+		case typemap.Boolean:
+			return "boolean"
+		case typemap.Int32:
+			return "integer"
+		case typemap.Int64:
+			return "bigint"
+		case typemap.Float:
+			return "real"
+		case typemap.Double:
+			return "double precision"
+		case typemap.Decimal:
+			return fmt.Sprintf("numeric(%d, %d)", t.Precision, t.Scale)
+		case typemap.Bytes:
+			return "bytea"
+		case typemap.Date:
+			return "date"
+		case typemap.Time:
+			return "time"
+		case typemap.Timestamp:
+			if strings.Contains(strings.ToLower(source), "with time zone") || strings.EqualFold(source, "timestamptz") || (g.source == BigQuery && strings.EqualFold(source, "TIMESTAMP")) {
+				return "timestamp with time zone"
+			}
+			return "timestamp"
+		case typemap.JSON:
+			return "jsonb"
+		case typemap.UUID:
+			return "uuid"
+		}
+		if length != "" {
+			return fmt.Sprintf("varchar(%s)", length)
+		}
+		return "text"
+	case MySQL:
+		switch t.Kind {
+		case typemap.Boolean:
+			return "tinyint(1)"
+		case typemap.Int32:
+			return "int"
+		case typemap.Int64:
+			return "bigint"
+		case typemap.Float:
+			return "float"
+		case typemap.Double:
+			return "double"
+		case typemap.Decimal:
+			return fmt.Sprintf("decimal(%d, %d)", clamp(t.Precision, 65), clamp(t.Scale, 30))
+		case typemap.Bytes:
+			return "blob"
+		case typemap.Date:
+			return "date"
+		case typemap.Time:
+			return "time"
+		case typemap.Timestamp:
+			return "datetime"
+		case typemap.JSON:
+			return "json"
+		case typemap.UUID:
+			return "char(36)"
+		case typemap.Enum:
+			symbols := []string{}
+			for _, s := range t.Symbols {
+				symbols = append(symbols, g.literal(s))
+			}
+			return fmt.Sprintf("enum(%s)", strings.Join(symbols, ","))
+		}
+		if length != "" {
+			return fmt.Sprintf("varchar(%s)", length)
+		}
+		return "text"
+	case SQLite:
+		switch t.Kind {
+		case typemap.Boolean, typemap.Int32, typemap.Int64:
+			return "integer"
+		case typemap.Float, typemap.Double:
+			return "real"
+		case typemap.Decimal:
+			return "numeric"
+		case typemap.Bytes:
+			return "blob"
+		case typemap.Date:
+			return "date"
+		case typemap.Time:
+			return "time"
+		case typemap.Timestamp:
+			return "datetime"
+		}
+		return "text"
+	case BigQuery:
+		switch t.Kind {
+		case typemap.Boolean:
+			return "BOOL"
+		case typemap.Int32, typemap.Int64:
+			return "INT64"
+		case typemap.Float, typemap.Double:
+			return "FLOAT64"
+		case typemap.Decimal:
+			if t.Precision-t.Scale > 29 || t.Scale > 9 {
+				return fmt.Sprintf("BIGNUMERIC(%d, %d)", clamp(t.Precision, 76), clamp(t.Scale, 38))
+			}
+			return fmt.Sprintf("NUMERIC(%d, %d)", t.Precision, t.Scale)
+		case typemap.Bytes:
+			return "BYTES"
+		case typemap.Date:
+			return "DATE"
+		case typemap.Time:
+			return "TIME"
+		case typemap.Timestamp:
+			return "TIMESTAMP"
+		case typemap.JSON:
+			return "JSON"
+		}
+		return "STRING"
+	}
+	return source
+}
+
+var (
+	numberRe     = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	quotedRe     = regexp.MustCompile(`^'((?:[^']|'')*)'(?:::[\w\s]+)?$`)
+	keywordRe    = regexp.MustCompile(`^(?i)(NULL|TRUE|FALSE)$`)
+	currentRe    = regexp.MustCompile(`^(?i)(CURRENT_TIMESTAMP|now)(\(\d*\))?$`)
+	expressionRe = regexp.MustCompile(`[()]`)
+)
+
+// defaultValue return default value of the target dialect.
+// Database specific expressions are output only when the source driver and the target dialect are the same.
+func (g *generator) defaultValue(f *typemap.Field) string {
+	c := f.Column
+	if !c.Default.Valid {
+		return ""
+	}
+	v := strings.TrimSpace(c.Default.String)
+	switch {
+	case f.Type.Kind == typemap.Boolean && (v == "0" || v == "1") && (g.dialect == Postgres || g.dialect == BigQuery):
+		if v == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case numberRe.MatchString(v):
+		return v
+	case keywordRe.MatchString(v):
+		return strings.ToUpper(v)
+	case currentRe.MatchString(v):
+		if g.dialect == BigQuery {
+			return "CURRENT_TIMESTAMP()"
+		}
+		return "CURRENT_TIMESTAMP"
+	}
+	if m := quotedRe.FindStringSubmatch(v); m != nil {
+		if g.same() {
+			return v
+		}
+		return g.literal(strings.Replace(m[1], "''", "'", -1))
+	}
+	if g.source == MySQL && !expressionRe.MatchString(v) {
+		// MySQL returns string default value without quotes
+		return g.literal(v)
+	}
+	if g.same() {
+		return v
+	}
+	return ""
+}
+
+var mysqlAutoIncrementRe = regexp.MustCompile("(?im)^\\s*`([^`]+)`.*\\bAUTO_INCREMENT\\b")
+
+func isAutoIncrement(source string, t *schema.Table, c *schema.Column) bool {
+	switch source {
+	case Postgres:
+		return c.Default.Valid && strings.HasPrefix(c.Default.String, "nextval(")
+	case MySQL:
+		for _, m := range mysqlAutoIncrementRe.FindAllStringSubmatch(t.Def, -1) {
+			if m[1] == c.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *generator) constraint(c *schema.Constraint) string {
+	name := ""
+	if c.Name != "" && c.Name != "PRIMARY" {
+		name = fmt.Sprintf("CONSTRAINT %s ", g.ident(c.Name))
+	}
+	switch c.Type {
+	case "PRIMARY KEY", "UNIQUE":
+		return fmt.Sprintf("%s%s (%s)", name, c.Type, g.idents(constraintColumns(c.Columns, c.Def)))
+	case "CHECK":
+		if g.same() {
+			return fmt.Sprintf("%s%s", name, c.Def)
+		}
+	}
+	return ""
+}
+
+func (g *generator) index(t *schema.Table, i *schema.Index) string {
+	def := strings.ToUpper(i.Def)
+	if strings.Contains(def, "PRIMARY KEY") {
+		return ""
+	}
+	for _, c := range t.Constraints {
+		if c.Name == i.Name && (c.Type == "PRIMARY KEY" || c.Type == "UNIQUE") {
+			return ""
+		}
+	}
+	if g.same() && g.dialect == Postgres {
+		return fmt.Sprintf("%s;", i.Def)
+	}
+	unique := ""
+	if strings.Contains(def, "UNIQUE") {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);", unique, g.ident(i.Name), g.tableName(t.Name), g.idents(constraintColumns(i.Columns, i.Def)))
+}
+
+func (g *generator) foreignKeys(tables []*schema.Table, relations []*schema.Relation) string {
+	if g.dialect == SQLite || g.dialect == BigQuery {
+		return ""
+	}
+	stmts := []string{}
+	for _, r := range relations {
+		if r.Virtual || !containsTable(tables, r.Table) {
+			continue
+		}
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD %s;", g.tableName(r.Table.Name), g.foreignKey(r)))
+	}
+	return strings.Join(stmts, "\n")
+}
+
+var (
+	referentialActionRe = regexp.MustCompile(`(?i)\bON\s+(DELETE|UPDATE)\s+(CASCADE|RESTRICT|NO\s+ACTION|SET\s+NULL|SET\s+DEFAULT)`)
+	referencesRe        = regexp.MustCompile(`(?i)\bREFERENCES\s+[^(]+\([^)]*\)\s*(.*)$`)
+)
+
+func (g *generator) foreignKey(r *schema.Relation) string {
+	name := ""
+	for _, c := range r.Table.Constraints {
+		if c.Type == schema.TypeFK && c.Def == r.Def && c.Name != "" {
+			name = fmt.Sprintf("CONSTRAINT %s ", g.ident(c.Name))
+		}
+	}
+	columns := []string{}
+	for _, c := range r.Columns {
+		columns = append(columns, c.Name)
+	}
+	parentColumns := []string{}
+	for _, c := range r.ParentColumns {
+		parentColumns = append(parentColumns, c.Name)
+	}
+	fk := fmt.Sprintf("%sFOREIGN KEY (%s) REFERENCES %s (%s)", name, g.idents(columns), g.tableName(r.ParentTable.Name), g.idents(parentColumns))
+	if g.same() {
+		if m := referencesRe.FindStringSubmatch(r.Def); m != nil && strings.TrimSpace(m[1]) != "" {
+			return fmt.Sprintf("%s %s", fk, strings.TrimSpace(m[1]))
+		}
+		return fk
+	}
+	for _, a := range referentialActionRe.FindAllString(r.Def, -1) {
+		fk = fmt.Sprintf("%s %s", fk, strings.ToUpper(a))
+	}
+	return fk
+}
+
+func (g *generator) createView(t *schema.Table) string {
+	def := strings.TrimSpace(t.Def)
+	if !g.same() || def == "" {
+		return fmt.Sprintf("-- %s %s is skipped: the definition can not be converted to %s", strings.ToLower(t.Type), t.Name, g.dialect)
+	}
+	if !strings.HasPrefix(strings.ToUpper(def), "CREATE") {
+		def = fmt.Sprintf("CREATE VIEW %s AS\n%s", g.tableName(t.Name), def)
+	}
+	return fmt.Sprintf("%s;", strings.TrimRight(def, ";"))
+}
+
+// comment return SQL comment lines. Only SQLite that has no COMMENT syntax uses it.
+func (g *generator) comment(comment, indent string) string {
+	if g.dialect != SQLite || comment == "" {
+		return ""
+	}
+	lines := []string{}
+	for _, l := range strings.Split(strings.Replace(comment, "\r\n", "\n", -1), "\n") {
+		lines = append(lines, strings.TrimRight(fmt.Sprintf("%s-- %s", indent, l), " "))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (g *generator) tableName(name string) string {
+	switch g.dialect {
+	case Postgres, MySQL:
+		ns, n := splitName(name)
+		if ns != "" {
+			return fmt.Sprintf("%s.%s", g.ident(ns), g.ident(n))
+		}
+	}
+	return g.ident(name)
+}
+
+func (g *generator) ident(v string) string {
+	switch g.dialect {
+	case MySQL, BigQuery:
+		return fmt.Sprintf("`%s`", strings.Replace(v, "`", "``", -1))
+	}
+	return fmt.Sprintf(`"%s"`, strings.Replace(v, `"`, `""`, -1))
+}
+
+func (g *generator) idents(vs []string) string {
+	quoted := []string{}
+	for _, v := range vs {
+		quoted = append(quoted, g.ident(v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (g *generator) literal(v string) string {
+	switch g.dialect {
+	case MySQL:
+		r := strings.NewReplacer(`\`, `\\`, `'`, `''`)
+		return fmt.Sprintf("'%s'", r.Replace(v))
+	case BigQuery:
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", `\r`, "\n", `\n`)
+		return fmt.Sprintf(`"%s"`, r.Replace(v))
+	}
+	return fmt.Sprintf("'%s'", strings.Replace(v, "'", "''", -1))
+}
+
+var columnsRe = regexp.MustCompile(`\(([^()]+)\)`)
+
+// constraintColumns return columns of constraint/index. Columns are parsed from the definition if the schema has no columns ( e.g. old schema.json ).
+func constraintColumns(columns []string, def string) []string {
+	if len(columns) > 0 && columns[0] != "" {
+		return columns
+	}
+	columns = []string{}
+	if m := columnsRe.FindStringSubmatch(def); m != nil {
+		for _, c := range strings.Split(m[1], ",") {
+			columns = append(columns, strings.Trim(strings.TrimSpace(c), "`\""))
+		}
+	}
+	return columns
+}
+
+func isView(t *schema.Table) bool {
+	return strings.Contains(strings.ToUpper(t.Type), "VIEW")
+}
+
+// splitName return namespace and name. e.g. `public.users` -> `public`, `users`
+func splitName(name string) (string, string) {
+	if i := strings.Index(name, "."); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// clamp return v limited to the maximum of the target database
+func clamp(v, limit int) int {
+	if v > limit {
+		return limit
+	}
+	return v
+}
+
+func contains(rs []*schema.Relation, e *schema.Relation) bool {
+	for _, r := range rs {
+		if e == r {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTable(ts []*schema.Table, e *schema.Table) bool {
+	for _, t := range ts {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
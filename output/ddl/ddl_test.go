@@ -0,0 +1,182 @@
+package ddl
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestOutputSchema(t *testing.T) {
+	for _, dialect := range Dialects {
+		s := newTestSchema()
+		c, err := config.NewConfig()
+		if err != nil {
+			t.Error(err)
+		}
+		o := NewDDL(c, s.Driver, dialect)
+		buf := &bytes.Buffer{}
+		err = o.OutputSchema(buf, s)
+		if err != nil {
+			t.Error(err)
+		}
+		expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), fmt.Sprintf("ddl_test_schema.%s.sql.golden", dialect)))
+		actual := buf.String()
+		if actual != string(expected) {
+			t.Errorf("%s: actual %v\nwant %v", dialect, actual, string(expected))
+		}
+	}
+}
+
+func TestOutputTable(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewDDL(c, s.Driver, "")
+	buf := &bytes.Buffer{}
+	err = o.OutputTable(buf, s.Tables[1])
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "ddl_test_posts.sql.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputSchemaExternal(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[0].External = true
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewDDL(c, s.Driver, MySQL)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	got := buf.String()
+	if strings.Contains(got, "CREATE TABLE `users`") {
+		t.Errorf("external table is created: %v", got)
+	}
+	if !strings.Contains(got, "REFERENCES `users`") {
+		t.Errorf("foreign key to external table is dropped: %v", got)
+	}
+}
+
+func TestUnsupportedDialect(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewDDL(c, s.Driver, "oracle")
+	err = o.OutputSchema(&bytes.Buffer{}, s)
+	if err == nil {
+		t.Error("got nil\nwant error")
+	}
+}
+
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
+
+func newTestSchema() *schema.Schema {
+	users := &schema.Table{
+		Name:    "users",
+		Type:    "BASE TABLE",
+		Comment: "Users table",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "int(11)"},
+			&schema.Column{Name: "email", Type: "varchar(255)", Comment: "login email"},
+			&schema.Column{Name: "status", Type: "varchar(10)", Default: sql.NullString{String: "active", Valid: true}},
+			&schema.Column{Name: "admin", Type: "tinyint(1)", Default: sql.NullString{String: "0", Valid: true}},
+		},
+		Def: "CREATE TABLE `users` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n  `email` varchar(255) NOT NULL COMMENT 'login email',\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB",
+	}
+	users.Constraints = []*schema.Constraint{
+		&schema.Constraint{Name: "PRIMARY", Type: "PRIMARY KEY", Def: "PRIMARY KEY (id)", Table: &users.Name, Columns: []string{"id"}},
+		&schema.Constraint{Name: "email", Type: "UNIQUE", Def: "UNIQUE KEY email (email)", Table: &users.Name, Columns: []string{"email"}},
+	}
+	users.Indexes = []*schema.Index{
+		&schema.Index{Name: "PRIMARY", Def: "PRIMARY KEY (id) USING BTREE", Table: &users.Name, Columns: []string{"id"}},
+		&schema.Index{Name: "email", Def: "UNIQUE KEY email (email) USING BTREE", Table: &users.Name, Columns: []string{"email"}},
+	}
+
+	posts := &schema.Table{
+		Name:    "posts",
+		Type:    "BASE TABLE",
+		Comment: "Posts table\nwith 'quote'",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "bigint(20)"},
+			&schema.Column{Name: "user_id", Type: "int(11)"},
+			&schema.Column{Name: "title", Type: "varchar(255)"},
+			&schema.Column{Name: "body", Type: "text", Nullable: true},
+			&schema.Column{Name: "post_type", Type: "enum('public','private','draft')", Comment: "public/private/draft"},
+			&schema.Column{Name: "price", Type: "decimal(10,2)", Nullable: true},
+			&schema.Column{Name: "created", Type: "datetime", Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}},
+		},
+		Def: "CREATE TABLE `posts` (\n  `id` bigint(20) NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB",
+	}
+	posts.Constraints = []*schema.Constraint{
+		&schema.Constraint{Name: "PRIMARY", Type: "PRIMARY KEY", Def: "PRIMARY KEY (id)", Table: &posts.Name, Columns: []string{"id"}},
+		&schema.Constraint{Name: "posts_user_id_fk", Type: schema.TypeFK, Def: "FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE", Table: &posts.Name, Columns: []string{"user_id"}, ReferenceTable: &users.Name, ReferenceColumns: []string{"id"}},
+	}
+	posts.Indexes = []*schema.Index{
+		&schema.Index{Name: "PRIMARY", Def: "PRIMARY KEY (id) USING BTREE", Table: &posts.Name, Columns: []string{"id"}},
+		&schema.Index{Name: "posts_user_id_idx", Def: "KEY posts_user_id_idx (user_id) USING BTREE", Table: &posts.Name, Columns: []string{"user_id"}},
+	}
+
+	view := &schema.Table{
+		Name: "post_titles",
+		Type: "VIEW",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "title", Type: "varchar(255)"},
+		},
+		Def: "CREATE VIEW post_titles AS (select `posts`.`title` AS `title` from `posts`)",
+	}
+
+	r := &schema.Relation{
+		Table:         posts,
+		Columns:       []*schema.Column{posts.Columns[1]},
+		ParentTable:   users,
+		ParentColumns: []*schema.Column{users.Columns[0]},
+		Def:           "FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE",
+	}
+	vr := &schema.Relation{
+		Table:         view,
+		Columns:       []*schema.Column{view.Columns[0]},
+		ParentTable:   posts,
+		ParentColumns: []*schema.Column{posts.Columns[2]},
+		Def:           "Additional Relation",
+		Virtual:       true,
+	}
+	posts.Columns[1].ParentRelations = []*schema.Relation{r}
+	users.Columns[0].ChildRelations = []*schema.Relation{r}
+	view.Columns[0].ParentRelations = []*schema.Relation{vr}
+	posts.Columns[2].ChildRelations = []*schema.Relation{vr}
+
+	return &schema.Schema{
+		Name:      "testschema",
+		Tables:    []*schema.Table{users, posts, view},
+		Relations: []*schema.Relation{r, vr},
+		Driver: &schema.Driver{
+			Name:            "mysql",
+			DatabaseVersion: "5.7.25",
+		},
+	}
+}
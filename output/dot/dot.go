@@ -1,9 +1,11 @@
 package dot
 
 import (
+	"bytes"
+	"fmt"
 	"io"
+	"os/exec"
 	"strings"
-	"text/template"
 
 	"github.com/gobuffalo/packr/v2"
 	"github.com/Melsoft-Games/tbls/config"
@@ -36,11 +38,10 @@ func NewDot(c *config.Config) *Dot {
 
 // OutputSchema output dot format for full relation.
 func (d *Dot) OutputSchema(wr io.Writer, s *schema.Schema) error {
-	ts, err := d.box.FindString("schema.dot.tmpl")
+	tmpl, err := config.LoadTemplate("schema.dot.tmpl", d.config.Templates.Dot.Schema, d.box.FindString, templateFuncs)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	tmpl := template.Must(template.New(s.Name).Funcs(templateFuncs).Parse(ts))
 	err = tmpl.Execute(wr, map[string]interface{}{
 		"Schema":      s,
 		"showComment": d.config.ER.Comment,
@@ -78,11 +79,10 @@ func (d *Dot) OutputTable(wr io.Writer, t *schema.Table) error {
 		}
 	}
 
-	ts, err := d.box.FindString("table.dot.tmpl")
+	tmpl, err := config.LoadTemplate("table.dot.tmpl", d.config.Templates.Dot.Table, d.box.FindString, templateFuncs)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	tmpl := template.Must(template.New(t.Name).Funcs(templateFuncs).Parse(ts))
 	err = tmpl.Execute(wr, map[string]interface{}{
 		"Table":       t,
 		"Tables":      tables,
@@ -104,3 +104,17 @@ func contains(rs []*schema.Relation, e *schema.Relation) bool {
 	}
 	return false
 }
+
+// Render render dot source to the format ( png, svg, ... ) using Graphviz `dot` command.
+func Render(wr io.Writer, src io.Reader, format string) error {
+	cmd := exec.Command("dot", fmt.Sprintf("-T%s", format)) // #nosec
+	var stderr bytes.Buffer
+	cmd.Stdin = src
+	cmd.Stdout = wr
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return errors.WithStack(errors.Wrap(err, stderr.String()))
+	}
+	return nil
+}
@@ -64,6 +64,29 @@ func TestOutputTable(t *testing.T) {
 	}
 }
 
+func TestOutputSchemaTemplate(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "templates_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewDot(c)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "dot_test_template_schema.dot.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
 func testdataDir() string {
 	wd, _ := os.Getwd()
 	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
@@ -0,0 +1,86 @@
+package er
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// ER struct
+type ER struct {
+	config *config.Config
+	format string
+}
+
+// NewER return ER.
+// ER renders ER diagrams without Graphviz, so it supports only svg and png.
+func NewER(c *config.Config, format string) *ER {
+	return &ER{
+		config: c,
+		format: format,
+	}
+}
+
+// Supported return true if the format can be rendered without Graphviz
+func Supported(format string) bool {
+	switch format {
+	case "svg", "png":
+		return true
+	}
+	return false
+}
+
+// OutputSchema output ER diagram for full relation.
+func (e *ER) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	return e.output(wr, nil, s.Tables, s.Relations)
+}
+
+// OutputTable output ER diagram for table.
+func (e *ER) OutputTable(wr io.Writer, t *schema.Table) error {
+	encountered := map[string]bool{t.Name: true}
+	tables := []*schema.Table{t}
+	relations := []*schema.Relation{}
+	for _, c := range t.Columns {
+		for _, r := range c.ParentRelations {
+			if !encountered[r.ParentTable.Name] {
+				encountered[r.ParentTable.Name] = true
+				tables = append(tables, r.ParentTable)
+			}
+			if !contains(relations, r) {
+				relations = append(relations, r)
+			}
+		}
+		for _, r := range c.ChildRelations {
+			if !encountered[r.Table.Name] {
+				encountered[r.Table.Name] = true
+				tables = append(tables, r.Table)
+			}
+			if !contains(relations, r) {
+				relations = append(relations, r)
+			}
+		}
+	}
+	return e.output(wr, t, tables, relations)
+}
+
+func (e *ER) output(wr io.Writer, main *schema.Table, tables []*schema.Table, relations []*schema.Relation) error {
+	switch e.format {
+	case "svg":
+		return writeSVG(wr, layout(main, tables, relations, e.config.ER.Comment, svgMetrics{}))
+	case "png":
+		return writePNG(wr, layout(main, tables, relations, e.config.ER.Comment, pngMetrics{}))
+	}
+	return errors.WithStack(fmt.Errorf("unsupported ER diagram format '%s'", e.format))
+}
+
+func contains(rs []*schema.Relation, e *schema.Relation) bool {
+	for _, r := range rs {
+		if e == r {
+			return true
+		}
+	}
+	return false
+}
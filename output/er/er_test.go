@@ -0,0 +1,200 @@
+package er
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestOutputSchema(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "out_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewER(c, "svg")
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "er_test_schema.svg.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputTable(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "out_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	ta := s.Tables[0]
+
+	o := NewER(c, "svg")
+	buf := &bytes.Buffer{}
+	_ = o.OutputTable(buf, ta)
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "er_test_a.svg.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputPNG(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewER(c, "png")
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() == 0 || img.Bounds().Dy() == 0 {
+		t.Errorf("actual %v\nwant non-empty image", img.Bounds())
+	}
+}
+
+func TestOutputSchemaExternal(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[0].External = true
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewER(c, "svg")
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "[external]") {
+		t.Errorf("external table is not marked: %v", got)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	s := newTestSchema()
+	d := layout(nil, s.Tables, s.Relations, false, svgMetrics{})
+	a := d.nodes[0]
+	b := d.nodes[1]
+	if a.rank != 0 || b.rank != 1 {
+		t.Errorf("actual %v, %v\nwant 0, 1", a.rank, b.rank)
+	}
+	if a.y+a.height >= b.y {
+		t.Errorf("child table should be placed above parent table: %v >= %v", a.y+a.height, b.y)
+	}
+
+	// cycle and self relation
+	r := &schema.Relation{
+		Table:         b.table,
+		Columns:       []*schema.Column{b.table.Columns[1]},
+		ParentTable:   a.table,
+		ParentColumns: []*schema.Column{a.table.Columns[1]},
+	}
+	self := &schema.Relation{
+		Table:         a.table,
+		Columns:       []*schema.Column{a.table.Columns[1]},
+		ParentTable:   a.table,
+		ParentColumns: []*schema.Column{a.table.Columns[0]},
+	}
+	d = layout(nil, s.Tables, append(s.Relations, r, self), false, svgMetrics{})
+	if len(d.edges) != 3 {
+		t.Errorf("actual %v\nwant %v", len(d.edges), 3)
+	}
+}
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
+
+func newTestSchema() *schema.Schema {
+	ca := &schema.Column{
+		Name:    "a",
+		Comment: "column a",
+	}
+	cb := &schema.Column{
+		Name:    "b",
+		Comment: "column b",
+	}
+
+	ta := &schema.Table{
+		Name:    "a",
+		Comment: "table a",
+		Columns: []*schema.Column{
+			ca,
+			&schema.Column{
+				Name:    "a2",
+				Comment: "column a2",
+			},
+		},
+	}
+	tb := &schema.Table{
+		Name:    "b",
+		Comment: "table b",
+		Columns: []*schema.Column{
+			cb,
+			&schema.Column{
+				Name:    "b2",
+				Comment: "column b2",
+			},
+		},
+	}
+	r := &schema.Relation{
+		Table:         ta,
+		Columns:       []*schema.Column{ca},
+		ParentTable:   tb,
+		ParentColumns: []*schema.Column{cb},
+	}
+	ca.ParentRelations = []*schema.Relation{r}
+	cb.ChildRelations = []*schema.Relation{r}
+
+	s := &schema.Schema{
+		Name: "testschema",
+		Tables: []*schema.Table{
+			ta,
+			tb,
+		},
+		Relations: []*schema.Relation{
+			r,
+		},
+		Driver: &schema.Driver{
+			Name:            "testdriver",
+			DatabaseVersion: "1.0.0",
+		},
+	}
+	return s
+}
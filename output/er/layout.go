@@ -0,0 +1,407 @@
+package er
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+const (
+	margin        = 20.0
+	padding       = 6.0
+	gapX          = 40.0
+	gapY          = 80.0
+	stub          = 20.0
+	crowLength    = 12.0
+	crowWidth     = 6.0
+	fontSize      = 14.0
+	titleFontSize = 18.0
+	labelFontSize = 10.0
+	sweeps        = 4
+)
+
+// metrics measure text for the output format
+type metrics interface {
+	textWidth(text string, size float64) float64
+	lineHeight(size float64) float64
+}
+
+type point struct {
+	x, y float64
+}
+
+type span struct {
+	text  string
+	size  float64
+	color string
+	bold  bool
+}
+
+type cell struct {
+	lines               [][]span
+	fill                string
+	x, y, width, height float64
+}
+
+type node struct {
+	table               *schema.Table
+	cells               []*cell
+	border              float64
+	rank, order         int
+	x, y, width, height float64
+}
+
+type label struct {
+	text   string
+	x, y   float64
+	anchor string
+}
+
+type edge struct {
+	points []point
+	marks  [][]point
+	dashed bool
+	label  label
+}
+
+type diagram struct {
+	width, height float64
+	nodes         []*node
+	edges         []*edge
+	metrics       metrics
+}
+
+// layout place tables ( as nodes ) in layers like Graphviz dot with rankdir=TB,
+// that is, child tables are placed above their parent tables.
+func layout(main *schema.Table, tables []*schema.Table, relations []*schema.Relation, showComment bool, m metrics) *diagram {
+	d := &diagram{metrics: m}
+	nodes := map[string]*node{}
+	for _, t := range tables {
+		n := newNode(t, showComment, m)
+		if t == main {
+			n.border = 3
+		}
+		nodes[t.Name] = n
+		d.nodes = append(d.nodes, n)
+	}
+	rels := []*schema.Relation{}
+	for _, r := range relations {
+		if nodes[r.Table.Name] == nil || nodes[r.ParentTable.Name] == nil {
+			continue
+		}
+		rels = append(rels, r)
+	}
+
+	ranks := rank(d.nodes, nodes, rels)
+	order(ranks, nodes, rels)
+	position(ranks)
+
+	for _, r := range rels {
+		d.edges = append(d.edges, route(r, nodes[r.Table.Name], nodes[r.ParentTable.Name], m))
+	}
+	d.fit()
+	return d
+}
+
+func newNode(t *schema.Table, showComment bool, m metrics) *node {
+	n := &node{table: t}
+	typ := t.Type
+	if t.External {
+		typ = "external"
+	}
+	title := []span{
+		{text: t.Name, size: titleFontSize, bold: true},
+		{text: fmt.Sprintf(" [%s]", typ), size: fontSize, color: "#666666"},
+	}
+	header := &cell{lines: [][]span{title}, fill: "#EFEFEF"}
+	if showComment && t.Comment != "" {
+		for _, l := range strings.Split(strings.Replace(t.Comment, "\r\n", "\n", -1), "\n") {
+			header.lines = append(header.lines, []span{{text: l, size: fontSize, color: "#333333"}})
+		}
+	}
+	n.cells = append(n.cells, header)
+	for _, c := range t.Columns {
+		spans := []span{
+			{text: c.Name, size: fontSize},
+			{text: fmt.Sprintf(" [%s]", c.Type), size: fontSize, color: "#666666"},
+		}
+		if showComment && c.Comment != "" {
+			r := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+			spans = append(spans, span{text: fmt.Sprintf(" %s", r.Replace(c.Comment)), size: fontSize})
+		}
+		n.cells = append(n.cells, &cell{lines: [][]span{spans}, fill: "#FFFFFF"})
+	}
+
+	for _, c := range n.cells {
+		c.height = padding * 2
+		for _, l := range c.lines {
+			w := 0.0
+			h := 0.0
+			for _, s := range l {
+				w += m.textWidth(s.text, s.size)
+				h = math.Max(h, m.lineHeight(s.size))
+			}
+			n.width = math.Max(n.width, w+padding*2)
+			c.height += h
+		}
+		n.height += c.height
+	}
+	for _, c := range n.cells {
+		c.width = n.width
+	}
+	return n
+}
+
+// rank assign the longest path rank from child tables so that a parent table is always placed below its child tables.
+// Relations that make a cycle are ignored.
+func rank(all []*node, nodes map[string]*node, rels []*schema.Relation) [][]*node {
+	children := map[*node][]*node{}
+	for _, r := range rels {
+		c := nodes[r.Table.Name]
+		p := nodes[r.ParentTable.Name]
+		if c == p {
+			continue
+		}
+		children[p] = append(children[p], c)
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*node]int{}
+	var visit func(n *node) int
+	visit = func(n *node) int {
+		switch state[n] {
+		case visiting:
+			return -1
+		case visited:
+			return n.rank
+		}
+		state[n] = visiting
+		n.rank = 0
+		for _, c := range children[n] {
+			if r := visit(c); r >= 0 && r+1 > n.rank {
+				n.rank = r + 1
+			}
+		}
+		state[n] = visited
+		return n.rank
+	}
+	max := 0
+	for _, n := range all {
+		if r := visit(n); r > max {
+			max = r
+		}
+	}
+	ranks := make([][]*node, max+1)
+	for _, n := range all {
+		n.order = len(ranks[n.rank])
+		ranks[n.rank] = append(ranks[n.rank], n)
+	}
+	return ranks
+}
+
+// order reduce edge crossings using the barycenter heuristic
+func order(ranks [][]*node, nodes map[string]*node, rels []*schema.Relation) {
+	neighbors := map[*node][]*node{}
+	for _, r := range rels {
+		c := nodes[r.Table.Name]
+		p := nodes[r.ParentTable.Name]
+		if c == p {
+			continue
+		}
+		neighbors[c] = append(neighbors[c], p)
+		neighbors[p] = append(neighbors[p], c)
+	}
+	sortRank := func(rank []*node, adjacent int) {
+		bary := map[*node]float64{}
+		for _, n := range rank {
+			sum := 0.0
+			count := 0
+			for _, nn := range neighbors[n] {
+				if nn.rank == adjacent {
+					sum += float64(nn.order)
+					count++
+				}
+			}
+			if count == 0 {
+				bary[n] = float64(n.order)
+				continue
+			}
+			bary[n] = sum / float64(count)
+		}
+		sort.SliceStable(rank, func(i, j int) bool {
+			return bary[rank[i]] < bary[rank[j]]
+		})
+		for i, n := range rank {
+			n.order = i
+		}
+	}
+	for i := 0; i < sweeps; i++ {
+		for r := 1; r < len(ranks); r++ {
+			sortRank(ranks[r], r-1)
+		}
+		for r := len(ranks) - 2; r >= 0; r-- {
+			sortRank(ranks[r], r+1)
+		}
+	}
+}
+
+func position(ranks [][]*node) {
+	widths := make([]float64, len(ranks))
+	max := 0.0
+	for i, rank := range ranks {
+		for j, n := range rank {
+			if j > 0 {
+				widths[i] += gapX
+			}
+			widths[i] += n.width
+		}
+		max = math.Max(max, widths[i])
+	}
+	y := margin
+	for i, rank := range ranks {
+		x := margin + (max-widths[i])/2
+		h := 0.0
+		for _, n := range rank {
+			n.x = x
+			n.y = y
+			cy := y
+			for _, c := range n.cells {
+				c.x = x
+				c.y = cy
+				cy += c.height
+			}
+			x += n.width + gapX
+			h = math.Max(h, n.height)
+		}
+		y += h + gapY
+	}
+}
+
+// route draw a relation from the child column to the parent column.
+// The crow's foot is on the child side like `arrowtail=crow` of Graphviz.
+func route(r *schema.Relation, c, p *node, m metrics) *edge {
+	cy := c.portY(r.Columns)
+	py := p.portY(r.ParentColumns)
+	e := &edge{dashed: r.Virtual}
+
+	var p0, p1, p2, p3 point
+	dir := 1.0
+	switch {
+	case c.x < p.x+p.width && p.x < c.x+c.width:
+		// self relation or tables placed one above the other
+		x := math.Max(c.x+c.width, p.x+p.width) + stub
+		p0 = point{c.x + c.width, cy}
+		p1 = point{x, cy}
+		p2 = point{x, py}
+		p3 = point{p.x + p.width, py}
+	case p.x+p.width/2 >= c.x+c.width/2:
+		p0 = point{c.x + c.width, cy}
+		p1 = point{p0.x + stub, cy}
+		p3 = point{p.x, py}
+		p2 = point{p3.x - stub, py}
+	default:
+		dir = -1.0
+		p0 = point{c.x, cy}
+		p1 = point{p0.x - stub, cy}
+		p3 = point{p.x + p.width, py}
+		p2 = point{p3.x + stub, py}
+	}
+	e.points = []point{p0, p1, p2, p3}
+
+	tip := point{p0.x + dir*crowLength, p0.y}
+	e.marks = [][]point{
+		{tip, {p0.x, p0.y - crowWidth}},
+		{tip, p0},
+		{tip, {p0.x, p0.y + crowWidth}},
+	}
+
+	e.label = label{
+		text:   r.Def,
+		x:      p0.x + dir*(crowLength+2),
+		y:      p0.y - 4,
+		anchor: "start",
+	}
+	if dir < 0 {
+		e.label.anchor = "end"
+	}
+	return e
+}
+
+// portY return the vertical center of the first column cell
+func (n *node) portY(columns []*schema.Column) float64 {
+	c := n.cells[0]
+	if len(columns) > 0 {
+		for i, col := range n.table.Columns {
+			if col == columns[0] || col.Name == columns[0].Name {
+				c = n.cells[i+1]
+				break
+			}
+		}
+	}
+	return c.y + c.height/2
+}
+
+// fit translate the diagram so that all nodes, edges and labels are in the canvas
+func (d *diagram) fit() {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	extend := func(x, y float64) {
+		minX = math.Min(minX, x)
+		minY = math.Min(minY, y)
+		maxX = math.Max(maxX, x)
+		maxY = math.Max(maxY, y)
+	}
+	for _, n := range d.nodes {
+		extend(n.x, n.y)
+		extend(n.x+n.width, n.y+n.height)
+	}
+	for _, e := range d.edges {
+		for _, p := range e.points {
+			extend(p.x, p.y)
+		}
+		if e.label.text != "" {
+			w := d.metrics.textWidth(e.label.text, labelFontSize)
+			x := e.label.x
+			if e.label.anchor == "end" {
+				x -= w
+			}
+			extend(x, e.label.y-d.metrics.lineHeight(labelFontSize))
+			extend(x+w, e.label.y)
+		}
+	}
+	if len(d.nodes) == 0 {
+		minX, minY, maxX, maxY = margin, margin, margin, margin
+	}
+	dx := margin - minX
+	dy := margin - minY
+	for _, n := range d.nodes {
+		n.x += dx
+		n.y += dy
+		for _, c := range n.cells {
+			c.x += dx
+			c.y += dy
+		}
+	}
+	for _, e := range d.edges {
+		for i := range e.points {
+			e.points[i].x += dx
+			e.points[i].y += dy
+		}
+		for _, m := range e.marks {
+			for i := range m {
+				m[i].x += dx
+				m[i].y += dy
+			}
+		}
+		e.label.x += dx
+		e.label.y += dy
+	}
+	d.width = math.Ceil(maxX - minX + margin*2)
+	d.height = math.Ceil(maxY - minY + margin*2)
+}
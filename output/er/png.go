@@ -0,0 +1,158 @@
+package er
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/pkg/errors"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// pngMetrics measure text by the fixed width bitmap font
+type pngMetrics struct{}
+
+func (pngMetrics) textWidth(text string, size float64) float64 {
+	return float64(runewidth.StringWidth(text) * basicfont.Face7x13.Advance)
+}
+
+func (pngMetrics) lineHeight(size float64) float64 {
+	return float64(basicfont.Face7x13.Height + 2)
+}
+
+func writePNG(wr io.Writer, d *diagram) error {
+	img := image.NewRGBA(image.Rect(0, 0, int(d.width), int(d.height)))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	black := color.Black
+
+	for _, e := range d.edges {
+		for i := 1; i < len(e.points); i++ {
+			drawLine(img, e.points[i-1], e.points[i], black, e.dashed)
+		}
+		for _, m := range e.marks {
+			for i := 1; i < len(m); i++ {
+				drawLine(img, m[i-1], m[i], black, false)
+			}
+		}
+		if e.label.text != "" {
+			x := e.label.x
+			if e.label.anchor == "end" {
+				x -= d.metrics.textWidth(e.label.text, labelFontSize)
+			}
+			drawText(img, e.label.text, x, e.label.y, black, false)
+		}
+	}
+
+	for _, n := range d.nodes {
+		for _, c := range n.cells {
+			r := image.Rect(round(c.x), round(c.y), round(c.x+c.width), round(c.y+c.height))
+			draw.Draw(img, r, image.NewUniform(parseColor(c.fill)), image.Point{}, draw.Src)
+			drawRect(img, r, black)
+			y := c.y + padding
+			for _, l := range c.lines {
+				x := c.x + padding
+				y += d.metrics.lineHeight(fontSize)
+				for _, s := range l {
+					drawText(img, s.text, x, y-3, parseColor(s.color), s.bold)
+					x += d.metrics.textWidth(s.text, s.size)
+				}
+			}
+		}
+		for i := 1; i < int(n.border); i++ {
+			drawRect(img, image.Rect(round(n.x)-i, round(n.y)-i, round(n.x+n.width)+i, round(n.y+n.height)+i), black)
+		}
+	}
+
+	err := png.Encode(wr, img)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func drawText(img draw.Image, text string, x, y float64, c color.Color, bold bool) {
+	dr := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(round(x), round(y)),
+	}
+	dr.DrawString(text)
+	if bold {
+		dr.Dot = fixed.P(round(x)+1, round(y))
+		dr.DrawString(text)
+	}
+}
+
+func drawRect(img draw.Image, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x <= r.Max.X; x++ {
+		img.Set(x, r.Min.Y, c)
+		img.Set(x, r.Max.Y, c)
+	}
+	for y := r.Min.Y; y <= r.Max.Y; y++ {
+		img.Set(r.Min.X, y, c)
+		img.Set(r.Max.X, y, c)
+	}
+}
+
+// drawLine draw a line using Bresenham's algorithm
+func drawLine(img draw.Image, from, to point, c color.Color, dashed bool) {
+	x0, y0 := round(from.x), round(from.y)
+	x1, y1 := round(to.x), round(to.y)
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for i := 0; ; i++ {
+		if !dashed || i%7 < 5 {
+			img.Set(x0, y0, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func parseColor(v string) color.Color {
+	if len(v) != 7 || v[0] != '#' {
+		return color.Black
+	}
+	rgb, err := strconv.ParseUint(v[1:], 16, 32)
+	if err != nil {
+		return color.Black
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}
+}
+
+func round(v float64) int {
+	return int(math.Round(v))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,103 @@
+package er
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/pkg/errors"
+)
+
+// svgMetrics approximate Arial glyph widths
+type svgMetrics struct{}
+
+func (svgMetrics) textWidth(text string, size float64) float64 {
+	return float64(runewidth.StringWidth(text)) * size * 0.6
+}
+
+func (svgMetrics) lineHeight(size float64) float64 {
+	return size * 1.2
+}
+
+func writeSVG(wr io.Writer, d *diagram) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="Arial, Helvetica, sans-serif">`+"\n", num(d.width), num(d.height), num(d.width), num(d.height))
+	buf.WriteString(`<rect width="100%" height="100%" fill="#FFFFFF"/>` + "\n")
+
+	for _, e := range d.edges {
+		dash := ""
+		if e.dashed {
+			dash = ` stroke-dasharray="5,2"`
+		}
+		buf.WriteString(`<g class="edge">` + "\n")
+		fmt.Fprintf(buf, `<polyline points="%s" fill="none" stroke="#000000"%s/>`+"\n", points(e.points), dash)
+		for _, m := range e.marks {
+			fmt.Fprintf(buf, `<polyline points="%s" fill="none" stroke="#000000"/>`+"\n", points(m))
+		}
+		if e.label.text != "" {
+			fmt.Fprintf(buf, `<text x="%s" y="%s" font-size="%s" text-anchor="%s">%s</text>`+"\n", num(e.label.x), num(e.label.y), num(labelFontSize), e.label.anchor, escape(e.label.text))
+		}
+		buf.WriteString("</g>\n")
+	}
+
+	for _, n := range d.nodes {
+		fmt.Fprintf(buf, `<g class="node" id="%s">`+"\n", escape(n.table.Name))
+		for _, c := range n.cells {
+			fmt.Fprintf(buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="#000000"/>`+"\n", num(c.x), num(c.y), num(c.width), num(c.height), c.fill)
+			y := c.y + padding
+			for _, l := range c.lines {
+				h := 0.0
+				for _, s := range l {
+					h = math.Max(h, d.metrics.lineHeight(s.size))
+				}
+				y += h
+				fmt.Fprintf(buf, `<text x="%s" y="%s" xml:space="preserve">`, num(c.x+padding), num(y-h*0.25))
+				for _, s := range l {
+					attrs := fmt.Sprintf(` font-size="%s"`, num(s.size))
+					if s.bold {
+						attrs += ` font-weight="bold"`
+					}
+					if s.color != "" {
+						attrs += fmt.Sprintf(` fill="%s"`, s.color)
+					}
+					fmt.Fprintf(buf, `<tspan%s>%s</tspan>`, attrs, escape(s.text))
+				}
+				buf.WriteString("</text>\n")
+			}
+		}
+		if n.border > 1 {
+			fmt.Fprintf(buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="none" stroke="#000000" stroke-width="%s"/>`+"\n", num(n.x), num(n.y), num(n.width), num(n.height), num(n.border))
+		}
+		buf.WriteString("</g>\n")
+	}
+	buf.WriteString("</svg>\n")
+
+	_, err := wr.Write(buf.Bytes())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+func points(ps []point) string {
+	s := []string{}
+	for _, p := range ps {
+		s = append(s, fmt.Sprintf("%s,%s", num(p.x), num(p.y)))
+	}
+	return strings.Join(s, " ")
+}
+
+func escape(v string) string {
+	buf := new(bytes.Buffer)
+	_ = xml.EscapeText(buf, []byte(v))
+	return buf.String()
+}
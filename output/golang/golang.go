@@ -0,0 +1,263 @@
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/typemap"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPackage = "models"
+	// NullableSQL use sql.Null* types for nullable columns
+	NullableSQL = "sql"
+	// NullablePointer use pointer types for nullable columns
+	NullablePointer = "pointer"
+)
+
+var defaultTags = []string{"db", "json"}
+
+// Golang struct
+type Golang struct {
+	config *config.Config
+	driver *schema.Driver
+}
+
+// NewGolang return Golang.
+// driver is used to map column types of the database.
+func NewGolang(c *config.Config, d *schema.Driver) *Golang {
+	return &Golang{
+		config: c,
+		driver: d,
+	}
+}
+
+// OutputSchema output Go structs for all tables except external tables.
+func (g *Golang) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	driver := g.driver
+	if s.Driver != nil {
+		driver = s.Driver
+	}
+	tables := []*schema.Table{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		tables = append(tables, t)
+	}
+	return g.output(wr, driverName(driver), tables)
+}
+
+// OutputTable output Go struct for table.
+func (g *Golang) OutputTable(wr io.Writer, t *schema.Table) error {
+	return g.output(wr, driverName(g.driver), []*schema.Table{t})
+}
+
+func (g *Golang) output(wr io.Writer, driver string, tables []*schema.Table) error {
+	c := g.config.Gen.Go
+	gen := &generator{
+		nullable: c.Nullable,
+		tags:     c.Tags,
+		imports:  map[string]bool{},
+		names:    map[string]bool{},
+	}
+	if gen.nullable == "" {
+		gen.nullable = NullableSQL
+	}
+	if gen.nullable != NullableSQL && gen.nullable != NullablePointer {
+		return errors.WithStack(fmt.Errorf("unsupported gen.go.nullable '%s'", gen.nullable))
+	}
+	if len(gen.tags) == 0 {
+		gen.tags = defaultTags
+	}
+	pkg := c.Package
+	if pkg == "" {
+		pkg = defaultPackage
+	}
+
+	// reserve the table struct names first so that nested types get the suffix on collision
+	names := []string{}
+	for _, t := range tables {
+		names = append(names, uniqueName(gen.names, typeName(t.Name)))
+	}
+
+	body := new(bytes.Buffer)
+	for i, t := range tables {
+		name := names[i]
+		comment := fmt.Sprintf("%s is the struct for table %s", name, t.Name)
+		if t.Comment != "" {
+			comment = fmt.Sprintf("%s\n%s", comment, t.Comment)
+		}
+		gen.structType(body, name, comment, typemap.Fields(driver, t))
+	}
+
+	src := new(bytes.Buffer)
+	fmt.Fprintf(src, "// Code generated by tbls. DO NOT EDIT.\n\npackage %s\n", pkg)
+	if len(gen.imports) > 0 {
+		imports := []string{}
+		for i := range gen.imports {
+			imports = append(imports, fmt.Sprintf("\t%q", i))
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(src, "\nimport (\n%s\n)\n", strings.Join(imports, "\n"))
+	}
+	_, _ = body.WriteTo(src)
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = wr.Write(formatted)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+type generator struct {
+	nullable string
+	tags     []string
+	imports  map[string]bool
+	names    map[string]bool
+}
+
+// structType write struct type. Nested types ( enum and RECORD ) are written after the struct.
+func (g *generator) structType(wr io.Writer, name, comment string, fields []*typemap.Field) {
+	nested := new(bytes.Buffer)
+	fieldNames := map[string]bool{}
+	fmt.Fprintf(wr, "\n%stype %s struct {\n", docComment(comment, ""), name)
+	for _, f := range fields {
+		fieldName := uniqueName(fieldNames, typeName(f.Name))
+		typ := ""
+		switch f.Type.Kind {
+		case typemap.Enum:
+			typ = uniqueName(g.names, name+fieldName)
+			g.enumType(nested, typ, f)
+		case typemap.Record:
+			typ = uniqueName(g.names, name+fieldName)
+			g.structType(nested, typ, fmt.Sprintf("%s is the struct for %s", typ, f.Column.Name), f.Fields)
+		}
+		tags := []string{}
+		for _, t := range g.tags {
+			tags = append(tags, fmt.Sprintf(`%s:"%s"`, t, f.Name))
+		}
+		fmt.Fprintf(wr, "%s\t%s %s `%s`\n", docComment(f.Column.Comment, "\t"), fieldName, g.fieldType(typ, f), strings.Join(tags, " "))
+	}
+	fmt.Fprintf(wr, "}\n")
+	_, _ = nested.WriteTo(wr)
+}
+
+func (g *generator) enumType(wr io.Writer, name string, f *typemap.Field) {
+	fmt.Fprintf(wr, "\n// %s is the enum for %s\ntype %s string\n\n// %s values\nconst (\n", name, f.Column.Name, name, name)
+	for _, s := range f.Type.Symbols {
+		fmt.Fprintf(wr, "\t%s %s = %q\n", uniqueName(g.names, name+typeName(s)), name, s)
+	}
+	fmt.Fprintf(wr, ")\n")
+}
+
+// fieldType return Go type of the field. named is the name of the nested type.
+func (g *generator) fieldType(named string, f *typemap.Field) string {
+	nullable := f.Nullable && !f.Type.Repeated
+	typ := ""
+	sqlType := ""
+	switch f.Type.Kind {
+	case typemap.Boolean:
+		typ, sqlType = "bool", "sql.NullBool"
+	case typemap.Int32:
+		typ, sqlType = "int32", "sql.NullInt32"
+	case typemap.Int64:
+		typ, sqlType = "int64", "sql.NullInt64"
+	case typemap.Float, typemap.Double:
+		typ, sqlType = "float64", "sql.NullFloat64"
+	case typemap.Bytes:
+		typ = "[]byte"
+		nullable = false
+	case typemap.Date, typemap.Timestamp:
+		g.imports["time"] = true
+		typ, sqlType = "time.Time", "sql.NullTime"
+	case typemap.JSON:
+		g.imports["encoding/json"] = true
+		typ = "json.RawMessage"
+		nullable = false
+	case typemap.Enum:
+		typ, sqlType = named, "sql.NullString"
+	case typemap.Record:
+		typ = named
+	case typemap.Unknown:
+		typ = "interface{}"
+		nullable = false
+	default:
+		typ, sqlType = "string", "sql.NullString"
+	}
+	switch {
+	case f.Type.Repeated:
+		typ = fmt.Sprintf("[]%s", typ)
+	case nullable && g.nullable == NullableSQL && sqlType != "":
+		g.imports["database/sql"] = true
+		typ = sqlType
+	case nullable:
+		typ = fmt.Sprintf("*%s", typ)
+	}
+	return typ
+}
+
+var (
+	wordRe      = regexp.MustCompile(`[A-Za-z0-9]+`)
+	initialisms = map[string]bool{
+		"API": true, "ID": true, "IP": true, "JSON": true, "HTML": true, "HTTP": true, "SQL": true,
+		"URI": true, "URL": true, "UUID": true, "UTC": true, "XML": true,
+	}
+)
+
+// typeName return exported Go identifier. e.g. `user_id` -> `UserID`
+func typeName(v string) string {
+	words := wordRe.FindAllString(v, -1)
+	for i, w := range words {
+		if initialisms[strings.ToUpper(w)] {
+			words[i] = strings.ToUpper(w)
+			continue
+		}
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	name := strings.Join(words, "")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = fmt.Sprintf("X%s", name)
+	}
+	return name
+}
+
+// uniqueName return name that is not in used, and mark it as used. e.g. `UserLog` -> `UserLog2`
+func uniqueName(used map[string]bool, name string) string {
+	n := name
+	for i := 2; used[n]; i++ {
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+	used[n] = true
+	return n
+}
+
+func docComment(comment, indent string) string {
+	if comment == "" {
+		return ""
+	}
+	lines := []string{}
+	for _, l := range strings.Split(strings.Replace(comment, "\r\n", "\n", -1), "\n") {
+		lines = append(lines, strings.TrimRight(fmt.Sprintf("%s// %s", indent, l), " "))
+	}
+	return fmt.Sprintf("%s\n", strings.Join(lines, "\n"))
+}
+
+func driverName(d *schema.Driver) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
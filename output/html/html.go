@@ -0,0 +1,341 @@
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output"
+	"github.com/Melsoft-Games/tbls/output/dot"
+	"github.com/Melsoft-Games/tbls/output/er"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/gobuffalo/packr/v2"
+	"github.com/pkg/errors"
+)
+
+const searchIndexFileName = "search-index.js"
+
+// HTML struct
+type HTML struct {
+	config *config.Config
+	er     bool
+	serve  bool
+	box    *packr.Box
+}
+
+// NewHTML return HTML
+func NewHTML(c *config.Config, er bool) *HTML {
+	return &HTML{
+		config: c,
+		er:     er,
+		box:    packr.New("html", "./templates"),
+	}
+}
+
+// NewServeHTML return HTML for `tbls serve`.
+// ER diagrams are referred as SVG images rendered on demand, and pages have a re-analyze button.
+func NewServeHTML(c *config.Config) *HTML {
+	h := NewHTML(c, false)
+	h.serve = true
+	return h
+}
+
+// OutputSchema output .html format for all tables.
+func (h *HTML) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	tmpl, err := h.template("index.html.tmpl")
+	if err != nil {
+		return err
+	}
+	templateData := map[string]interface{}{
+		"Schema":      s,
+		"Title":       s.Name,
+		"Serve":       h.serve,
+		"TableLabels": hasTableLabels(s),
+	}
+	if h.serve {
+		templateData["ERImage"] = "schema.svg"
+	}
+	if h.er {
+		svg, err := h.renderSVG(func(o output.Output, wr io.Writer) error {
+			return o.OutputSchema(wr, s)
+		})
+		if err != nil {
+			return err
+		}
+		templateData["ER"] = svg
+	}
+	err = tmpl.ExecuteTemplate(wr, "index.html.tmpl", templateData)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// OutputTable output .html format for table.
+func (h *HTML) OutputTable(wr io.Writer, t *schema.Table) error {
+	tmpl, err := h.template("table.html.tmpl")
+	if err != nil {
+		return err
+	}
+	templateData := map[string]interface{}{
+		"Table":        t,
+		"Title":        t.Name,
+		"Columns":      makeColumnRows(t),
+		"ColumnLabels": hasColumnLabels(t),
+		"Serve":        h.serve,
+	}
+	if h.serve {
+		templateData["ERImage"] = fmt.Sprintf("%s.svg", t.FileName())
+	}
+	if h.er {
+		svg, err := h.renderSVG(func(o output.Output, wr io.Writer) error {
+			return o.OutputTable(wr, t)
+		})
+		if err != nil {
+			return err
+		}
+		templateData["ER"] = svg
+	}
+	err = tmpl.ExecuteTemplate(wr, "table.html.tmpl", templateData)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Output generate static HTML documentation site.
+func Output(s *schema.Schema, c *config.Config, force bool) error {
+	docPath := c.DocPath
+
+	fullPath, err := filepath.Abs(docPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !force && outputExists(s, fullPath) {
+		return errors.New("output files already exists")
+	}
+
+	err = os.MkdirAll(fullPath, 0755) // #nosec
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	h := NewHTML(c, !c.ER.Skip)
+
+	// index.html
+	err = outputFile(filepath.Join(fullPath, "index.html"), func(wr io.Writer) error {
+		return h.OutputSchema(wr, s)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", filepath.Join(docPath, "index.html"))
+
+	// search-index.js
+	err = outputFile(filepath.Join(fullPath, searchIndexFileName), func(wr io.Writer) error {
+		return OutputSearchIndex(wr, s)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", filepath.Join(docPath, searchIndexFileName))
+
+	// tables
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		fileName := fmt.Sprintf("%s.html", t.FileName())
+		err = outputFile(filepath.Join(fullPath, fileName), func(wr io.Writer) error {
+			return h.OutputTable(wr, t)
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", filepath.Join(docPath, fileName))
+	}
+	return nil
+}
+
+func (h *HTML) template(name string) (*template.Template, error) {
+	tmpl := template.New(name).Funcs(funcMap())
+	for _, n := range []string{"layout.html.tmpl", name} {
+		ts, err := h.box.FindString(n)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		tmpl = template.Must(tmpl.New(n).Parse(ts))
+	}
+	return tmpl, nil
+}
+
+func outputFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = write(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func outputExists(s *schema.Schema, path string) bool {
+	// index.html
+	if _, err := os.Lstat(filepath.Join(path, "index.html")); err == nil {
+		return true
+	}
+	// tables
+	for _, t := range s.Tables {
+		if _, err := os.Lstat(filepath.Join(path, fmt.Sprintf("%s.html", t.FileName()))); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// searchEntry is the entry of client-side search index
+type searchEntry struct {
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	Href    string `json:"href"`
+}
+
+// OutputSearchIndex output search index as JavaScript so that it can be loaded from file:// too.
+func OutputSearchIndex(wr io.Writer, s *schema.Schema) error {
+	entries := []searchEntry{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		href := fmt.Sprintf("%s.html", t.FileName())
+		entries = append(entries, searchEntry{
+			Table:   t.Name,
+			Comment: t.Comment,
+			Href:    href,
+		})
+		for _, c := range t.Columns {
+			entries = append(entries, searchEntry{
+				Table:   t.Name,
+				Column:  c.Name,
+				Comment: c.Comment,
+				Href:    fmt.Sprintf("%s#column-%s", href, c.Name),
+			})
+		}
+	}
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = fmt.Fprintf(wr, "var tblsSearchIndex = %s;\n", b)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ColumnRow is the column of table page.
+// Nested columns ( e.g. BigQuery RECORD `a.b` ) are rendered as collapsible children of the parent column.
+type ColumnRow struct {
+	Column      *schema.Column
+	Label       string
+	Depth       int
+	Parent      string
+	HasChildren bool
+}
+
+func makeColumnRows(t *schema.Table) []*ColumnRow {
+	rows := []*ColumnRow{}
+	names := map[string]*ColumnRow{}
+	for _, c := range t.Columns {
+		row := &ColumnRow{
+			Column: c,
+			Label:  c.Name,
+		}
+		// find the nearest parent column by name prefix
+		for i := strings.LastIndex(c.Name, "."); i > 0; i = strings.LastIndex(c.Name[:i], ".") {
+			if p, ok := names[c.Name[:i]]; ok {
+				p.HasChildren = true
+				row.Parent = p.Column.Name
+				row.Depth = p.Depth + 1
+				row.Label = c.Name[i+1:]
+				break
+			}
+		}
+		names[c.Name] = row
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func hasTableLabels(s *schema.Schema) bool {
+	for _, t := range s.Tables {
+		if len(t.Labels) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasColumnLabels(t *schema.Table) bool {
+	for _, c := range t.Columns {
+		if len(c.Labels) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// renderSVG render ER diagram as SVG using Graphviz `dot` command if available, otherwise using native renderer.
+func (h *HTML) renderSVG(write func(output.Output, io.Writer) error) (template.HTML, error) {
+	out := new(bytes.Buffer)
+	if _, err := exec.LookPath("dot"); err == nil {
+		src := new(bytes.Buffer)
+		err := write(dot.NewDot(h.config), src)
+		if err != nil {
+			return "", err
+		}
+		err = dot.Render(out, src, "svg")
+		if err != nil {
+			return "", err
+		}
+	} else {
+		err := write(er.NewER(h.config, "svg"), out)
+		if err != nil {
+			return "", err
+		}
+	}
+	svg := out.String()
+	// strip XML declaration and DOCTYPE for inline SVG
+	if i := strings.Index(svg, "<svg"); i >= 0 {
+		svg = svg[i:]
+	}
+	return template.HTML(svg), nil // #nosec
+}
+
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		"nl2br": func(text string) template.HTML {
+			r := strings.NewReplacer("\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+			return template.HTML(r.Replace(template.HTMLEscapeString(text))) // #nosec
+		},
+		"indent": func(depth int) string {
+			return fmt.Sprintf("padding-left: %dem", depth*2+1)
+		},
+	}
+}
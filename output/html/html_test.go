@@ -0,0 +1,159 @@
+package html
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestOutputTable(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "out_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewHTML(c, false)
+	buf := &bytes.Buffer{}
+	err = o.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "html_test_a.html.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputTableLabels(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "labels_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewHTML(c, false)
+	buf := &bytes.Buffer{}
+	err = o.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	for _, want := range []string{
+		"<h2>Labels</h2>\n<p><span class=\"label\">owner: team-growth</span><span class=\"label\">deprecated</span></p>",
+		"<th>Labels</th>",
+		"<td><span class=\"label\">pii</span><span class=\"label\">retention: 90d</span></td>",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("actual %v\nwant contains %v", buf.String(), want)
+		}
+	}
+}
+
+var columnRowsTests = []struct {
+	name        string
+	label       string
+	depth       int
+	parent      string
+	hasChildren bool
+}{
+	{"event", "event", 0, "", true},
+	{"event.params", "params", 1, "event", true},
+	{"event.params.key", "key", 2, "event.params", false},
+	{"event.name", "name", 1, "event", false},
+	{"user.id", "user.id", 0, "", false},
+}
+
+func TestMakeColumnRows(t *testing.T) {
+	table := &schema.Table{Name: "events"}
+	for _, tt := range columnRowsTests {
+		table.Columns = append(table.Columns, &schema.Column{Name: tt.name})
+	}
+	rows := makeColumnRows(table)
+	for i, tt := range columnRowsTests {
+		r := rows[i]
+		if r.Label != tt.label || r.Depth != tt.depth || r.Parent != tt.parent || r.HasChildren != tt.hasChildren {
+			t.Errorf("actual %v %v %v %v\nwant %v %v %v %v", r.Label, r.Depth, r.Parent, r.HasChildren, tt.label, tt.depth, tt.parent, tt.hasChildren)
+		}
+	}
+}
+
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
+
+func newTestSchema() *schema.Schema {
+	ca := &schema.Column{
+		Name:    "a",
+		Comment: "column a",
+	}
+	cb := &schema.Column{
+		Name:    "b",
+		Comment: "column b",
+	}
+
+	ta := &schema.Table{
+		Name:    "a",
+		Comment: "table a",
+		Columns: []*schema.Column{
+			ca,
+			&schema.Column{
+				Name:    "a2",
+				Comment: "column a2",
+			},
+		},
+	}
+	tb := &schema.Table{
+		Name:    "b",
+		Comment: "table b",
+		Columns: []*schema.Column{
+			cb,
+			&schema.Column{
+				Name:    "b2",
+				Comment: "column b2",
+			},
+		},
+	}
+	r := &schema.Relation{
+		Table:         ta,
+		Columns:       []*schema.Column{ca},
+		ParentTable:   tb,
+		ParentColumns: []*schema.Column{cb},
+	}
+	ca.ParentRelations = []*schema.Relation{r}
+	cb.ChildRelations = []*schema.Relation{r}
+
+	s := &schema.Schema{
+		Name: "testschema",
+		Tables: []*schema.Table{
+			ta,
+			tb,
+		},
+		Relations: []*schema.Relation{
+			r,
+		},
+	}
+	return s
+}
@@ -0,0 +1,198 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/typemap"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+const draft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema struct
+type JSONSchema struct {
+	config *config.Config
+	driver *schema.Driver
+}
+
+// NewJSONSchema return JSONSchema.
+// driver is used to map column types of the database.
+func NewJSONSchema(c *config.Config, d *schema.Driver) *JSONSchema {
+	return &JSONSchema{
+		config: c,
+		driver: d,
+	}
+}
+
+// OutputSchema output JSON Schema which has all tables except external tables as definitions.
+func (j *JSONSchema) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	driver := j.driver
+	if s.Driver != nil {
+		driver = s.Driver
+	}
+	definitions := object{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		definitions = append(definitions, member{t.Name, table(driverName(driver), t)})
+	}
+	doc := object{
+		{"$schema", draft},
+		{"title", s.Name},
+		{"definitions", definitions},
+	}
+	return write(wr, doc)
+}
+
+// OutputTable output JSON Schema of table.
+func (j *JSONSchema) OutputTable(wr io.Writer, t *schema.Table) error {
+	doc := append(object{{"$schema", draft}}, table(driverName(j.driver), t)...)
+	return write(wr, doc)
+}
+
+func table(driver string, t *schema.Table) object {
+	o := object{{"title", t.Name}}
+	if t.Comment != "" {
+		o = append(o, member{"description", t.Comment})
+	}
+	return append(o, record(typemap.Fields(driver, t))...)
+}
+
+func record(fields []*typemap.Field) object {
+	properties := object{}
+	required := []string{}
+	for _, f := range fields {
+		properties = append(properties, member{f.Name, property(f)})
+		if !f.Nullable && !f.Type.Repeated {
+			required = append(required, f.Name)
+		}
+	}
+	o := object{
+		{"type", "object"},
+		{"properties", properties},
+	}
+	if len(required) > 0 {
+		o = append(o, member{"required", required})
+	}
+	return o
+}
+
+func property(f *typemap.Field) object {
+	p := item(f)
+	if f.Type.Repeated {
+		p = object{
+			{"type", "array"},
+			{"items", p},
+		}
+	} else if f.Nullable {
+		p = nullable(p)
+	}
+	if f.Column.Comment != "" {
+		p = append(p, member{"description", f.Column.Comment})
+	}
+	return p
+}
+
+func item(f *typemap.Field) object {
+	switch f.Type.Kind {
+	case typemap.Boolean:
+		return object{{"type", "boolean"}}
+	case typemap.Int32, typemap.Int64:
+		return object{{"type", "integer"}}
+	case typemap.Float, typemap.Double, typemap.Decimal:
+		return object{{"type", "number"}}
+	case typemap.Bytes:
+		return object{{"type", "string"}, {"contentEncoding", "base64"}}
+	case typemap.Date:
+		return object{{"type", "string"}, {"format", "date"}}
+	case typemap.Time:
+		return object{{"type", "string"}, {"format", "time"}}
+	case typemap.Timestamp:
+		return object{{"type", "string"}, {"format", "date-time"}}
+	case typemap.UUID:
+		return object{{"type", "string"}, {"format", "uuid"}}
+	case typemap.Enum:
+		symbols := []interface{}{}
+		for _, s := range f.Type.Symbols {
+			symbols = append(symbols, s)
+		}
+		return object{{"type", "string"}, {"enum", symbols}}
+	case typemap.Record:
+		return record(f.Fields)
+	case typemap.JSON, typemap.Unknown:
+		return object{}
+	}
+	return object{{"type", "string"}}
+}
+
+// nullable allow null for the type
+func nullable(o object) object {
+	n := object{}
+	for _, m := range o {
+		switch m.key {
+		case "type":
+			m.value = []string{m.value.(string), "null"}
+		case "enum":
+			m.value = append(m.value.([]interface{}), nil)
+		}
+		n = append(n, m)
+	}
+	return n
+}
+
+func driverName(d *schema.Driver) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
+
+func write(wr io.Writer, doc object) error {
+	enc := json.NewEncoder(wr)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(doc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+// object is JSON object that keeps the order of members
+type object []member
+
+// MarshalJSON return JSON object keeping the order of members
+func (o object) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("{")
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		err := enc.Encode(m.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteString(":")
+		err = enc.Encode(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
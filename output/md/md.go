@@ -7,11 +7,16 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
+	"unicode/utf8"
 
 	"github.com/gobuffalo/packr/v2"
 	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/columns"
+	"github.com/Melsoft-Games/tbls/output/dot"
+	"github.com/Melsoft-Games/tbls/output/mermaid"
 	"github.com/Melsoft-Games/tbls/schema"
 	"github.com/mattn/go-runewidth"
 	"github.com/pkg/errors"
@@ -22,28 +27,41 @@ import (
 type Md struct {
 	config *config.Config
 	er     bool
+	driver *schema.Driver
 	box    *packr.Box
 }
 
-// NewMd return Md
-func NewMd(c *config.Config, er bool) *Md {
+// NewMd return Md.
+// driver is used to choose the default columns of table document.
+func NewMd(c *config.Config, er bool, d *schema.Driver) *Md {
 	return &Md{
 		config: c,
 		er:     er,
+		driver: d,
 		box:    packr.New("md", "./templates"),
 	}
 }
 
 // OutputSchema output .md format for all tables.
 func (m *Md) OutputSchema(wr io.Writer, s *schema.Schema) error {
-	ts, err := m.box.FindString("index.md.tmpl")
+	tmpl, err := config.LoadTemplate("index.md.tmpl", m.config.Templates.MD.Index, m.box.FindString, funcMap())
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	tmpl := template.Must(template.New("index").Funcs(funcMap()).Parse(ts))
 	templateData := makeSchemaTemplateData(s, m.config.Format.Adjust)
+	if len(m.config.Viewpoints) > 0 {
+		templateData["Viewpoints"] = makeViewpointsData(m.config.Viewpoints, m.config.Format.Adjust)
+	}
 	templateData["er"] = m.er
 	templateData["erFormat"] = m.config.ER.Format
+	if m.er && m.config.ER.Format == config.ERFormatMermaid {
+		buf := new(bytes.Buffer)
+		err = mermaid.NewMermaid(m.config).OutputSchema(buf, s)
+		if err != nil {
+			return err
+		}
+		templateData["mermaid"] = buf.String()
+	}
 	err = tmpl.Execute(wr, templateData)
 	if err != nil {
 		return errors.WithStack(err)
@@ -53,14 +71,37 @@ func (m *Md) OutputSchema(wr io.Writer, s *schema.Schema) error {
 
 // OutputTable output md format for table.
 func (m *Md) OutputTable(wr io.Writer, t *schema.Table) error {
-	ts, err := m.box.FindString("table.md.tmpl")
+	tmpl, err := config.LoadTemplate("table.md.tmpl", m.config.Templates.MD.Table, m.box.FindString, funcMap())
 	if err != nil {
-		return errors.WithStack(err)
+		return err
+	}
+	cols, err := columns.Columns(m.config, m.driver)
+	if err != nil {
+		return err
 	}
-	tmpl := template.Must(template.New(t.Name).Funcs(funcMap()).Parse(ts))
-	templateData := makeTableTemplateData(t, m.config.Format.Adjust)
+	ct, err := columns.Rows(cols, t, columns.HideEmpty(m.config, m.driver), columns.Format{
+		Link: func(t *schema.Table) string {
+			return fmt.Sprintf("[%s](%s.md)", t.Name, t.FileName())
+		},
+		Label: func(l *schema.Label) string {
+			return fmt.Sprintf("`%s`", l)
+		},
+		Sep: " ",
+	})
+	if err != nil {
+		return err
+	}
+	templateData := makeTableTemplateData(t, ct, m.config.Format.Adjust)
 	templateData["er"] = m.er
 	templateData["erFormat"] = m.config.ER.Format
+	if m.er && m.config.ER.Format == config.ERFormatMermaid {
+		buf := new(bytes.Buffer)
+		err = mermaid.NewMermaid(m.config).OutputTable(buf, t)
+		if err != nil {
+			return err
+		}
+		templateData["mermaid"] = buf.String()
+	}
 
 	err = tmpl.Execute(wr, templateData)
 	if err != nil {
@@ -70,6 +111,37 @@ func (m *Md) OutputTable(wr io.Writer, t *schema.Table) error {
 	return nil
 }
 
+// OutputViewpoint output md format for the i-th viewpoint.
+func (m *Md) OutputViewpoint(wr io.Writer, i int, s *schema.Schema) error {
+	v := m.config.Viewpoints[i]
+	vs, err := v.Schema(s)
+	if err != nil {
+		return err
+	}
+	tmpl, err := config.LoadTemplate("viewpoint.md.tmpl", m.config.Templates.MD.Viewpoint, m.box.FindString, funcMap())
+	if err != nil {
+		return err
+	}
+	templateData := makeSchemaTemplateData(vs, m.config.Format.Adjust)
+	templateData["Viewpoint"] = v
+	templateData["er"] = m.er
+	templateData["erName"] = v.FileName()
+	templateData["erFormat"] = m.config.ER.Format
+	if m.er && m.config.ER.Format == config.ERFormatMermaid {
+		buf := new(bytes.Buffer)
+		err = mermaid.NewMermaid(m.config).OutputSchema(buf, vs)
+		if err != nil {
+			return err
+		}
+		templateData["mermaid"] = buf.String()
+	}
+	err = tmpl.Execute(wr, templateData)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 // Output generate markdown files.
 func Output(s *schema.Schema, c *config.Config, force bool) error {
 	docPath := c.DocPath
@@ -94,12 +166,9 @@ func Output(s *schema.Schema, c *config.Config, force bool) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	er := false
-	if _, err := os.Lstat(filepath.Join(fullPath, fmt.Sprintf("schema.%s", c.ER.Format))); err == nil {
-		er = true
-	}
+	er := erExists(c, fullPath, "schema")
 
-	md := NewMd(c, er)
+	md := NewMd(c, er, s.Driver)
 
 	err = md.OutputSchema(file, s)
 	if err != nil {
@@ -107,27 +176,52 @@ func Output(s *schema.Schema, c *config.Config, force bool) error {
 	}
 	fmt.Printf("%s\n", filepath.Join(docPath, "README.md"))
 
+	// viewpoints
+	for i, v := range c.Viewpoints {
+		fileName := fmt.Sprintf("%s.md", v.FileName())
+		file, err := os.Create(filepath.Join(fullPath, fileName))
+		if err != nil {
+			_ = file.Close()
+			return errors.WithStack(err)
+		}
+
+		er := erExists(c, fullPath, v.FileName())
+
+		md := NewMd(c, er, s.Driver)
+
+		err = md.OutputViewpoint(file, i, s)
+		if err != nil {
+			_ = file.Close()
+			return errors.WithStack(err)
+		}
+		fmt.Printf("%s\n", filepath.Join(docPath, fileName))
+		err = file.Close()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	// tables
 	for _, t := range s.Tables {
-		file, err := os.Create(filepath.Join(fullPath, fmt.Sprintf("%s.md", t.Name)))
+		if t.External {
+			continue
+		}
+		file, err := os.Create(filepath.Join(fullPath, fmt.Sprintf("%s.md", t.FileName())))
 		if err != nil {
 			_ = file.Close()
 			return errors.WithStack(err)
 		}
 
-		er := false
-		if _, err := os.Lstat(filepath.Join(fullPath, fmt.Sprintf("%s.%s", t.Name, c.ER.Format))); err == nil {
-			er = true
-		}
+		er := erExists(c, fullPath, t.FileName())
 
-		md := NewMd(c, er)
+		md := NewMd(c, er, s.Driver)
 
 		err = md.OutputTable(file, t)
 		if err != nil {
 			_ = file.Close()
 			return errors.WithStack(err)
 		}
-		fmt.Printf("%s\n", filepath.Join(docPath, fmt.Sprintf("%s.md", t.Name)))
+		fmt.Printf("%s\n", filepath.Join(docPath, fmt.Sprintf("%s.md", t.FileName())))
 		err = file.Close()
 		if err != nil {
 			return errors.WithStack(err)
@@ -136,97 +230,468 @@ func Output(s *schema.Schema, c *config.Config, force bool) error {
 	return nil
 }
 
-// Diff database and markdown files.
+// Kinds of difference between database and document
+const (
+	DiffKindDoc     = "doc"
+	DiffKindComment = "comment"
+	DiffKindER      = "er"
+)
+
+// FileDiff is the difference between database and a document file
+type FileDiff struct {
+	From  string
+	To    string
+	Table string
+	Text  string
+	Hunks []*Hunk
+}
+
+// Hunk is a hunk of the difference between database and a document file
+type Hunk struct {
+	File  string `json:"file"`
+	Table string `json:"table"`
+	Kind  string `json:"kind"`
+	Hunk  string `json:"hunk"`
+}
+
+// String return unified diff text with `diff` header
+func (d *FileDiff) String() string {
+	return fmt.Sprintf("diff %s %s\n%s", d.From, d.To, d.Text)
+}
+
+// Diff database and markdown files ( and ER diagram dot sources ).
 func Diff(s *schema.Schema, c *config.Config) (string, error) {
+	diffs, err := DiffFiles(s, c)
+	if err != nil {
+		return "", err
+	}
+	var diff string
+	for _, d := range diffs {
+		diff += d.String()
+	}
+	return diff, nil
+}
+
+// DiffFiles return the differences between database and markdown files per file.
+// ER diagrams are compared with the dot sources ( schema.dot, table.dot ) written by `tbls doc`.
+func DiffFiles(s *schema.Schema, c *config.Config) ([]*FileDiff, error) {
 	docPath := c.DocPath
 
-	var diff string
+	diffs := []*FileDiff{}
 	fullPath, err := filepath.Abs(docPath)
 	if err != nil {
-		return "", errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 
 	if !outputExists(s, fullPath) {
-		return "", errors.New("target files does not exists")
+		return nil, errors.New("target files does not exists")
 	}
 
 	// README.md
 	a := new(bytes.Buffer)
-	er := false
-	if _, err := os.Lstat(filepath.Join(fullPath, fmt.Sprintf("schema.%s", c.ER.Format))); err == nil {
-		er = true
-	}
+	er := erExists(c, fullPath, "schema")
 
-	md := NewMd(c, er)
+	md := NewMd(c, er, s.Driver)
 
-	err = md.OutputSchema(a, s)
+	commentHeaders, err := commentColumnHeaders(c, s.Driver)
 	if err != nil {
-		return "", errors.WithStack(err)
+		return nil, err
 	}
 
-	targetPath := filepath.Join(fullPath, "README.md")
-	b, err := ioutil.ReadFile(filepath.Clean(targetPath))
+	err = md.OutputSchema(a, s)
 	if err != nil {
-		b = []byte{}
+		return nil, errors.WithStack(err)
 	}
 
 	from, err := c.MaskedDSN()
 	if err != nil {
-		return "", errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
-	to := filepath.Join(docPath, "README.md")
 
-	d := difflib.UnifiedDiff{
-		A:        difflib.SplitLines(a.String()),
-		B:        difflib.SplitLines(string(b)),
-		FromFile: from,
-		ToFile:   to,
-		Context:  3,
+	d, err := diffFile(a.String(), fullPath, docPath, "README.md", from, "", "", commentHeaders)
+	if err != nil {
+		return nil, err
+	}
+	if d != nil {
+		diffs = append(diffs, d)
 	}
 
-	text, _ := difflib.GetUnifiedDiffString(d)
-	if text != "" {
-		diff += fmt.Sprintf("diff %s %s\n", from, to)
-		diff += text
+	// schema.dot
+	var dt *dot.Dot
+	if _, err := os.Lstat(filepath.Join(fullPath, "schema.dot")); err == nil {
+		dt = dot.NewDot(c)
+		a := new(bytes.Buffer)
+		err := dt.OutputSchema(a, s)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		d, err := diffFile(a.String(), fullPath, docPath, "schema.dot", from, "", DiffKindER, commentHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			diffs = append(diffs, d)
+		}
+	}
+
+	// viewpoints
+	for i, v := range c.Viewpoints {
+		name := v.FileName()
+		a := new(bytes.Buffer)
+		er := erExists(c, fullPath, name)
+
+		md := NewMd(c, er, s.Driver)
+
+		err := md.OutputViewpoint(a, i, s)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		d, err := diffFile(a.String(), fullPath, docPath, fmt.Sprintf("%s.md", name), from, "", "", commentHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			diffs = append(diffs, d)
+		}
+
+		// viewpoint.dot
+		dotFileName := fmt.Sprintf("%s.dot", name)
+		if _, err := os.Lstat(filepath.Join(fullPath, dotFileName)); err == nil {
+			if dt == nil {
+				dt = dot.NewDot(c)
+			}
+			vs, err := c.Viewpoints[i].Schema(s)
+			if err != nil {
+				return nil, err
+			}
+			a := new(bytes.Buffer)
+			err = dt.OutputSchema(a, vs)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			d, err := diffFile(a.String(), fullPath, docPath, dotFileName, from, "", DiffKindER, commentHeaders)
+			if err != nil {
+				return nil, err
+			}
+			if d != nil {
+				diffs = append(diffs, d)
+			}
+		}
 	}
 
 	// tables
 	for _, t := range s.Tables {
-		a := new(bytes.Buffer)
-		er := false
-		if _, err := os.Lstat(filepath.Join(fullPath, fmt.Sprintf("%s.%s", t.Name, c.ER.Format))); err == nil {
-			er = true
+		if t.External {
+			continue
 		}
+		a := new(bytes.Buffer)
+		er := erExists(c, fullPath, t.FileName())
 
-		md := NewMd(c, er)
+		md := NewMd(c, er, s.Driver)
 
 		err := md.OutputTable(a, t)
 		if err != nil {
-			return "", errors.WithStack(err)
+			return nil, errors.WithStack(err)
 		}
-		targetPath := filepath.Join(fullPath, fmt.Sprintf("%s.md", t.Name))
-		b, err := ioutil.ReadFile(filepath.Clean(targetPath))
+
+		d, err := diffFile(a.String(), fullPath, docPath, fmt.Sprintf("%s.md", t.FileName()), from, t.Name, "", commentHeaders)
 		if err != nil {
-			b = []byte{}
+			return nil, err
 		}
+		if d != nil {
+			diffs = append(diffs, d)
+		}
+
+		// table.dot
+		dotFileName := fmt.Sprintf("%s.dot", t.FileName())
+		if _, err := os.Lstat(filepath.Join(fullPath, dotFileName)); err == nil {
+			if dt == nil {
+				dt = dot.NewDot(c)
+			}
+			a := new(bytes.Buffer)
+			err := dt.OutputTable(a, t)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			d, err := diffFile(a.String(), fullPath, docPath, dotFileName, from, t.Name, DiffKindER, commentHeaders)
+			if err != nil {
+				return nil, err
+			}
+			if d != nil {
+				diffs = append(diffs, d)
+			}
+		}
+	}
+	return diffs, nil
+}
 
-		to := filepath.Join(docPath, fmt.Sprintf("%s.md", t.Name))
+// diffFile diff generated text and the document file.
+// If kind is empty, the kind of each hunk is classified from the markdown.
+func diffFile(a, fullPath, docPath, fileName, from, table, kind string, commentHeaders map[string]bool) (*FileDiff, error) {
+	targetPath := filepath.Join(fullPath, fileName)
+	b, err := ioutil.ReadFile(filepath.Clean(targetPath))
+	if err != nil {
+		b = []byte{}
+	}
+	to := filepath.Join(docPath, fileName)
 
-		d := difflib.UnifiedDiff{
-			A:        difflib.SplitLines(a.String()),
-			B:        difflib.SplitLines(string(b)),
-			FromFile: from,
-			ToFile:   to,
-			Context:  3,
+	aLines := difflib.SplitLines(a)
+	bLines := difflib.SplitLines(string(b))
+	ud := difflib.UnifiedDiff{
+		A:        aLines,
+		B:        bLines,
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	}
+	text, err := difflib.GetUnifiedDiffString(ud)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	d := &FileDiff{
+		From:  from,
+		To:    to,
+		Table: table,
+		Text:  text,
+	}
+	for _, h := range splitHunks(text) {
+		k := kind
+		if k == "" {
+			k = DiffKindDoc
+			if len(b) > 0 {
+				k = classifyHunk(h, aLines, bLines, commentHeaders)
+			}
 		}
+		d.Hunks = append(d.Hunks, &Hunk{
+			File:  to,
+			Table: table,
+			Kind:  k,
+			Hunk:  h,
+		})
+	}
+	return d, nil
+}
 
-		text, _ := difflib.GetUnifiedDiffString(d)
-		if text != "" {
-			diff += fmt.Sprintf("diff %s %s\n", from, to)
-			diff += text
+// splitHunks split unified diff text into hunks ( without file header )
+func splitHunks(text string) []string {
+	hunks := []string{}
+	current := ""
+	for _, l := range strings.SplitAfter(text, "\n") {
+		switch {
+		case strings.HasPrefix(l, "--- ") && current == "":
+		case strings.HasPrefix(l, "+++ ") && current == "":
+		case strings.HasPrefix(l, "@@ "):
+			if current != "" {
+				hunks = append(hunks, current)
+			}
+			current = l
+		default:
+			current += l
 		}
 	}
-	return diff, nil
+	if current != "" {
+		hunks = append(hunks, current)
+	}
+	return hunks
+}
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+const (
+	lineDescription = "description"
+	lineER          = "er"
+)
+
+// classifyHunk return the kind of the change in the hunk.
+// A hunk is DiffKindComment when every changed line differs only in table/column comments,
+// and DiffKindER when it changes the ER diagram section.
+func classifyHunk(hunk string, aLines, bLines []string, commentHeaders map[string]bool) string {
+	lines := strings.SplitAfter(strings.TrimSuffix(hunk, "\n"), "\n")
+	m := hunkHeaderRe.FindStringSubmatch(lines[0])
+	if m == nil {
+		return DiffKindDoc
+	}
+	ai := hunkStart(m[1], m[2])
+	bi := hunkStart(m[3], m[4])
+	aKinds := lineKinds(aLines, commentHeaders)
+	bKinds := lineKinds(bLines, commentHeaders)
+
+	doc := false
+	er := false
+	removed := []int{}
+	added := []int{}
+	flush := func() {
+		ra := []int{}
+		rb := []int{}
+		for _, i := range removed {
+			switch {
+			case aKinds[i] == lineER:
+				er = true
+			case aKinds[i] != lineDescription && strings.TrimSpace(aLines[i]) != "":
+				ra = append(ra, i)
+			}
+		}
+		for _, i := range added {
+			switch {
+			case bKinds[i] == lineER:
+				er = true
+			case bKinds[i] != lineDescription && strings.TrimSpace(bLines[i]) != "":
+				rb = append(rb, i)
+			}
+		}
+		if !commentOnlyChange(ra, rb, aLines, bLines, aKinds, bKinds, commentHeaders) {
+			doc = true
+		}
+		removed = []int{}
+		added = []int{}
+	}
+	for _, l := range lines[1:] {
+		switch {
+		case strings.HasPrefix(l, "-"):
+			removed = append(removed, ai)
+			ai++
+		case strings.HasPrefix(l, "+"):
+			added = append(added, bi)
+			bi++
+		default:
+			flush()
+			ai++
+			bi++
+		}
+	}
+	flush()
+
+	switch {
+	case doc:
+		return DiffKindDoc
+	case er:
+		return DiffKindER
+	}
+	return DiffKindComment
+}
+
+func hunkStart(start, length string) int {
+	var s int
+	_, _ = fmt.Sscanf(start, "%d", &s)
+	if length == "0" {
+		return s
+	}
+	return s - 1
+}
+
+// commentOnlyChange report whether the paired table rows differ only in comment columns
+func commentOnlyChange(ra, rb []int, aLines, bLines []string, aKinds, bKinds map[int]string, commentHeaders map[string]bool) bool {
+	if len(ra) != len(rb) {
+		return false
+	}
+	for k := range ra {
+		header := aKinds[ra[k]]
+		if header == "" || header != bKinds[rb[k]] {
+			return false
+		}
+		hs := splitRow(header)
+		ca := splitRow(aLines[ra[k]])
+		cb := splitRow(bLines[rb[k]])
+		if len(ca) != len(hs) || len(cb) != len(hs) {
+			return false
+		}
+		for j := range hs {
+			if !commentHeaders[hs[j]] && ca[j] != cb[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lineKinds mark the lines of markdown document.
+// Description lines are marked lineDescription, lines of Relations section are marked lineER,
+// and rows of tables which have comment column are marked with the header row.
+func lineKinds(lines []string, commentHeaders map[string]bool) map[int]string {
+	m := map[int]string{}
+	section := ""
+	details := false
+	header := ""
+	for i, l := range lines {
+		l = strings.TrimRight(l, "\r\n")
+		if strings.HasPrefix(l, "## ") {
+			section = strings.TrimPrefix(l, "## ")
+			header = ""
+		}
+		switch {
+		case section == "Relations":
+			m[i] = lineER
+		case l == "<details>":
+			details = true
+		case l == "</details>":
+			details = false
+		case strings.HasPrefix(l, "|"):
+			if header == "" {
+				header = l
+				continue
+			}
+			if strings.Trim(l, "|- ") == "" {
+				continue
+			}
+			for _, c := range splitRow(header) {
+				if commentHeaders[c] {
+					m[i] = header
+				}
+			}
+		case section == "Description" && !details && !strings.HasPrefix(l, "## ") && strings.TrimSpace(l) != "":
+			m[i] = lineDescription
+		default:
+			header = ""
+		}
+	}
+	return m
+}
+
+// commentColumnHeaders return the headers of table columns that are comments.
+// The header of the built-in Comment column may be changed by `format.columns:`,
+// and the header used by other columns is not a comment even if it is "Comment".
+func commentColumnHeaders(c *config.Config, d *schema.Driver) (map[string]bool, error) {
+	cols, err := columns.Columns(c, d)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]bool{columns.Comment: true}
+	for _, col := range cols {
+		if col.Builtin() == columns.Comment {
+			headers[col.Header] = true
+		}
+	}
+	for _, col := range cols {
+		if col.Builtin() != columns.Comment {
+			delete(headers, col.Header)
+		}
+	}
+	return headers, nil
+}
+
+func splitRow(row string) []string {
+	cells := strings.Split(strings.Trim(strings.TrimSpace(row), "|"), "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// erExists return true if the ER diagram of the name is available.
+// Mermaid ER diagrams are embedded in the document, so they need no image file.
+func erExists(c *config.Config, path, name string) bool {
+	if c.ER.Format == config.ERFormatMermaid {
+		return !c.ER.Skip
+	}
+	if _, err := os.Lstat(filepath.Join(path, fmt.Sprintf("%s.%s", name, c.ER.Format))); err == nil {
+		return true
+	}
+	return false
 }
 
 func outputExists(s *schema.Schema, path string) bool {
@@ -236,7 +701,7 @@ func outputExists(s *schema.Schema, path string) bool {
 	}
 	// tables
 	for _, t := range s.Tables {
-		if _, err := os.Lstat(filepath.Join(path, fmt.Sprintf("%s.md", t.Name))); err == nil {
+		if _, err := os.Lstat(filepath.Join(path, fmt.Sprintf("%s.md", t.FileName()))); err == nil {
 			return true
 		}
 	}
@@ -261,13 +726,33 @@ func makeSchemaTemplateData(s *schema.Schema, adjust bool) map[string]interface{
 		[]string{"Name", "Columns", "Comment", "Type"},
 		[]string{"----", "-------", "-------", "----"},
 	}
+	hasLabels := false
 	for _, t := range s.Tables {
+		if len(t.Labels) > 0 {
+			hasLabels = true
+		}
+	}
+	if hasLabels {
+		tablesData[0] = append(tablesData[0], "Labels")
+		tablesData[1] = append(tablesData[1], "------")
+	}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
 		data := []string{
-			fmt.Sprintf("[%s](%s.md)", t.Name, t.Name),
+			fmt.Sprintf("[%s](%s.md)", t.Name, t.FileName()),
 			fmt.Sprintf("%d", len(t.Columns)),
 			t.Comment,
 			t.Type,
 		}
+		if hasLabels {
+			labels := []string{}
+			for _, l := range t.Labels {
+				labels = append(labels, fmt.Sprintf("`%s`", l))
+			}
+			data = append(data, strings.Join(labels, " "))
+		}
 		tablesData = append(tablesData, data)
 	}
 
@@ -284,42 +769,34 @@ func makeSchemaTemplateData(s *schema.Schema, adjust bool) map[string]interface{
 	}
 }
 
-func makeTableTemplateData(t *schema.Table, adjust bool) map[string]interface{} {
+func makeViewpointsData(viewpoints []config.Viewpoint, adjust bool) [][]string {
+	data := [][]string{
+		[]string{"Name", "Description"},
+		[]string{"----", "-----------"},
+	}
+	for _, v := range viewpoints {
+		data = append(data, []string{
+			fmt.Sprintf("[%s](%s.md)", v.Name, v.FileName()),
+			v.Desc,
+		})
+	}
+	if adjust {
+		return adjustTable(data)
+	}
+	return data
+}
+
+func makeTableTemplateData(t *schema.Table, ct *columns.Table, adjust bool) map[string]interface{} {
 	// Columns
+	separator := []string{}
+	for _, h := range ct.Header {
+		separator = append(separator, strings.Repeat("-", utf8.RuneCountInString(h)))
+	}
 	columnsData := [][]string{
-		[]string{"Name", "Type", "Default", "Nullable", "Children", "Parents", "Comment"},
-		[]string{"----", "----", "-------", "--------", "--------", "-------", "-------"},
-	}
-	for _, c := range t.Columns {
-		childRelations := []string{}
-		cEncountered := map[string]bool{}
-		for _, r := range c.ChildRelations {
-			if _, ok := cEncountered[r.Table.Name]; ok {
-				continue
-			}
-			childRelations = append(childRelations, fmt.Sprintf("[%s](%s.md)", r.Table.Name, r.Table.Name))
-			cEncountered[r.Table.Name] = true
-		}
-		parentRelations := []string{}
-		pEncountered := map[string]bool{}
-		for _, r := range c.ParentRelations {
-			if _, ok := pEncountered[r.ParentTable.Name]; ok {
-				continue
-			}
-			parentRelations = append(parentRelations, fmt.Sprintf("[%s](%s.md)", r.ParentTable.Name, r.ParentTable.Name))
-			pEncountered[r.ParentTable.Name] = true
-		}
-		data := []string{
-			c.Name,
-			c.Type,
-			c.Default.String,
-			fmt.Sprintf("%v", c.Nullable),
-			strings.Join(childRelations, " "),
-			strings.Join(parentRelations, " "),
-			c.Comment,
-		}
-		columnsData = append(columnsData, data)
+		ct.Header,
+		separator,
 	}
+	columnsData = append(columnsData, ct.Rows...)
 
 	// Constraints
 	constraintsData := [][]string{
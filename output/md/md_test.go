@@ -1,13 +1,17 @@
 package md
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/dot"
 	"github.com/Melsoft-Games/tbls/schema"
 )
 
@@ -60,6 +64,222 @@ func TestOutput(t *testing.T) {
 	}
 }
 
+func TestOutputTemplate(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "templates_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	md := NewMd(c, false, s.Driver)
+	buf := &bytes.Buffer{}
+	err = md.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "md_test_template_a.md.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputColumns(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "columns_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	md := NewMd(c, false, s.Driver)
+	buf := &bytes.Buffer{}
+	err = md.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "md_test_columns_a.md.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputLabels(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "labels_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	md := NewMd(c, false, s.Driver)
+
+	buf := &bytes.Buffer{}
+	err = md.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "md_test_labels_README.md.golden"))
+	if buf.String() != string(expected) {
+		t.Errorf("actual %v\nwant %v", buf.String(), string(expected))
+	}
+
+	buf = &bytes.Buffer{}
+	err = md.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ = ioutil.ReadFile(filepath.Join(testdataDir(), "md_test_labels_a.md.golden"))
+	if buf.String() != string(expected) {
+		t.Errorf("actual %v\nwant %v", buf.String(), string(expected))
+	}
+}
+
+func TestOutputViewpoints(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	tempDir, _ := ioutil.TempDir("", "tbls")
+	defer os.RemoveAll(tempDir)
+	err = c.Load(filepath.Join(testdataDir(), "viewpoints_test_tbls.yml"), config.DocPath(tempDir))
+	if err != nil {
+		t.Error(err)
+	}
+	err = Output(s, c, true)
+	if err != nil {
+		t.Error(err)
+	}
+	for _, tt := range []struct {
+		actualFile   string
+		expectedFile string
+	}{
+		{"README.md", "md_test_viewpoints_README.md.golden"},
+		{"viewpoint-table-a.md", "md_test_viewpoint-table-a.md.golden"},
+		{"viewpoint-all.md", "md_test_viewpoint-all.md.golden"},
+	} {
+		expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), tt.expectedFile))
+		actual, err := ioutil.ReadFile(filepath.Join(tempDir, tt.actualFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("actual %v\nwant %v", string(actual), string(expected))
+		}
+	}
+}
+
+func TestOutputExternal(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	tempDir, _ := ioutil.TempDir("", "tbls")
+	defer os.RemoveAll(tempDir)
+	err = c.Load(filepath.Join(testdataDir(), "external_test_tbls.yml"), config.DocPath(tempDir))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.ModifySchema(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Output(s, c, true)
+	if err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Lstat(filepath.Join(tempDir, "b.md")); err == nil {
+		t.Error("document of external table should not be output")
+	}
+	readme, err := ioutil.ReadFile(filepath.Join(tempDir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(readme), "[b](b.md)") {
+		t.Errorf("external table should not be listed: %s", readme)
+	}
+	a, err := ioutil.ReadFile(filepath.Join(tempDir, "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(a), "(b.md)") || !strings.Contains(string(a), "| b |") {
+		t.Errorf("external table should not be linked: %s", a)
+	}
+}
+
+func TestOutputShard(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[0].Shard = &schema.Shard{First: "20200101", Last: "20201231", Count: 366}
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	md := NewMd(c, false, s.Driver)
+	buf := &bytes.Buffer{}
+	err = md.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	want := "## Shards\n\n`20200101` - `20201231` ( 366 tables )\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("actual %v\nwant %v", buf.String(), want)
+	}
+}
+
+func TestOutputWildcardFileName(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[0].Name = "a_*"
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	tempDir, _ := ioutil.TempDir("", "tbls")
+	defer os.RemoveAll(tempDir)
+	err = c.Load(filepath.Join(testdataDir(), "out_test_tbls.yml"), config.DocPath(tempDir))
+	if err != nil {
+		t.Error(err)
+	}
+	err = Output(s, c, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "a__.md")); err != nil {
+		t.Error(err)
+	}
+	readme, _ := ioutil.ReadFile(filepath.Join(tempDir, "README.md"))
+	if want := "[a_*](a__.md)"; !strings.Contains(string(readme), want) {
+		t.Errorf("actual %v\nwant %v", string(readme), want)
+	}
+}
+
+func TestTemplateNotFound(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	c.Templates.MD.Index = filepath.Join(testdataDir(), "templates", "notfound.md.tmpl")
+	md := NewMd(c, false, s.Driver)
+	err = md.OutputSchema(&bytes.Buffer{}, s)
+	if err == nil {
+		t.Error("got nil\nwant error")
+	}
+}
+
 func TestDiff(t *testing.T) {
 	for _, tt := range tests {
 		s := newTestSchema()
@@ -95,6 +315,133 @@ func TestDiff(t *testing.T) {
 	}
 }
 
+var diffKindTests = []struct {
+	name     string
+	modify   func(s *schema.Schema)
+	expected []string
+}{
+	{"no change", func(s *schema.Schema) {}, []string{}},
+	{"column comment", func(s *schema.Schema) { s.Tables[0].Columns[1].Comment = "changed" }, []string{DiffKindComment}},
+	{"table comment", func(s *schema.Schema) { s.Tables[1].Comment = "changed" }, []string{DiffKindComment, DiffKindComment}},
+	{"column type", func(s *schema.Schema) { s.Tables[0].Columns[1].Type = "text" }, []string{DiffKindDoc}},
+}
+
+func TestDiffFiles(t *testing.T) {
+	for _, tt := range diffKindTests {
+		s := newTestSchema()
+		c, err := config.NewConfig()
+		if err != nil {
+			t.Error(err)
+		}
+		tempDir, _ := ioutil.TempDir("", "tbls")
+		defer os.RemoveAll(tempDir)
+		err = c.Load(filepath.Join(testdataDir(), "out_test_tbls.yml"), config.DocPath(tempDir))
+		if err != nil {
+			t.Error(err)
+		}
+		err = c.MergeAdditionalData(s)
+		if err != nil {
+			t.Error(err)
+		}
+		err = Output(s, c, true)
+		if err != nil {
+			t.Error(err)
+		}
+		tt.modify(s)
+		diffs, err := DiffFiles(s, c)
+		if err != nil {
+			t.Error(err)
+		}
+		actual := []string{}
+		for _, d := range diffs {
+			for _, h := range d.Hunks {
+				actual = append(actual, h.Kind)
+			}
+		}
+		if fmt.Sprintf("%v", actual) != fmt.Sprintf("%v", tt.expected) {
+			t.Errorf("%s: actual %v\nwant %v", tt.name, actual, tt.expected)
+		}
+	}
+}
+
+func TestDiffFilesCommentHeader(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	tempDir, _ := ioutil.TempDir("", "tbls")
+	defer os.RemoveAll(tempDir)
+	err = c.Load(filepath.Join(testdataDir(), "out_test_tbls.yml"), config.DocPath(tempDir))
+	if err != nil {
+		t.Error(err)
+	}
+	c.Format.Columns = []config.ColumnFormat{{Name: "Name"}, {Name: "Type"}, {Name: "Comment", Header: "Description"}}
+	err = Output(s, c, true)
+	if err != nil {
+		t.Error(err)
+	}
+	s.Tables[0].Columns[1].Comment = "changed"
+	diffs, err := DiffFiles(s, c)
+	if err != nil {
+		t.Error(err)
+	}
+	actual := []string{}
+	for _, d := range diffs {
+		for _, h := range d.Hunks {
+			actual = append(actual, h.Kind)
+		}
+	}
+	if want := fmt.Sprintf("%v", []string{DiffKindComment}); fmt.Sprintf("%v", actual) != want {
+		t.Errorf("actual %v\nwant %v", actual, want)
+	}
+}
+
+func TestDiffFilesER(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	tempDir, _ := ioutil.TempDir("", "tbls")
+	defer os.RemoveAll(tempDir)
+	err = c.Load(filepath.Join(testdataDir(), "out_test_tbls.yml"), config.DocPath(tempDir))
+	if err != nil {
+		t.Error(err)
+	}
+	err = Output(s, c, true)
+	if err != nil {
+		t.Error(err)
+	}
+	// packr resolves the box of output/dot from the working directory in tests, so the template is given explicitly
+	c.Templates.Dot.Schema = filepath.Join(filepath.Dir(testdataDir()), "output", "dot", "templates", "schema.dot.tmpl")
+	file, err := os.Create(filepath.Join(tempDir, "schema.dot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dot.NewDot(c).OutputSchema(file, s)
+	_ = file.Close()
+	if err != nil {
+		t.Error(err)
+	}
+	c.ER.Comment = true
+	diffs, err := DiffFiles(s, c)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("actual %v\nwant %v", len(diffs), 1)
+	}
+	if diffs[0].To != filepath.Join(tempDir, "schema.dot") {
+		t.Errorf("actual %v\nwant %v", diffs[0].To, filepath.Join(tempDir, "schema.dot"))
+	}
+	for _, h := range diffs[0].Hunks {
+		if h.Kind != DiffKindER {
+			t.Errorf("actual %v\nwant %v", h.Kind, DiffKindER)
+		}
+	}
+}
+
 func testdataDir() string {
 	wd, _ := os.Getwd()
 	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
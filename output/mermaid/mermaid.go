@@ -0,0 +1,163 @@
+package mermaid
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// Mermaid struct
+type Mermaid struct {
+	config *config.Config
+}
+
+// NewMermaid return Mermaid
+func NewMermaid(c *config.Config) *Mermaid {
+	return &Mermaid{
+		config: c,
+	}
+}
+
+// OutputSchema output Mermaid erDiagram format for full relation.
+func (m *Mermaid) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	return m.output(wr, s.Tables, s.Relations)
+}
+
+// OutputTable output Mermaid erDiagram format for table.
+func (m *Mermaid) OutputTable(wr io.Writer, t *schema.Table) error {
+	encountered := map[string]bool{t.Name: true}
+	tables := []*schema.Table{t}
+	relations := []*schema.Relation{}
+	for _, c := range t.Columns {
+		for _, r := range c.ParentRelations {
+			if !encountered[r.ParentTable.Name] {
+				encountered[r.ParentTable.Name] = true
+				tables = append(tables, r.ParentTable)
+			}
+			if !contains(relations, r) {
+				relations = append(relations, r)
+			}
+		}
+		for _, r := range c.ChildRelations {
+			if !encountered[r.Table.Name] {
+				encountered[r.Table.Name] = true
+				tables = append(tables, r.Table)
+			}
+			if !contains(relations, r) {
+				relations = append(relations, r)
+			}
+		}
+	}
+	return m.output(wr, tables, relations)
+}
+
+func (m *Mermaid) output(wr io.Writer, tables []*schema.Table, relations []*schema.Relation) error {
+	lines := []string{"erDiagram"}
+	for _, t := range tables {
+		if t.External {
+			lines = append(lines, "  %% external table")
+		}
+		lines = append(lines, fmt.Sprintf("  %s {", entity(t.Name)))
+		for _, c := range t.Columns {
+			lines = append(lines, fmt.Sprintf("    %s", m.attribute(t, c)))
+		}
+		lines = append(lines, "  }")
+	}
+	for _, r := range relations {
+		lines = append(lines, fmt.Sprintf("  %s", relationship(r)))
+	}
+	_, err := fmt.Fprintf(wr, "%s\n", strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (m *Mermaid) attribute(t *schema.Table, c *schema.Column) string {
+	typ := c.Type
+	if typ == "" {
+		typ = "unknown"
+	}
+	attr := fmt.Sprintf("%s %s", identifier(typ), identifier(c.Name))
+	keys := []string{}
+	if t.InPrimaryKey(c.Name) {
+		keys = append(keys, "PK")
+	}
+	if len(c.ParentRelations) > 0 {
+		keys = append(keys, "FK")
+	}
+	if len(keys) > 0 {
+		attr = fmt.Sprintf("%s %s", attr, strings.Join(keys, ","))
+	}
+	if m.config.ER.Comment && c.Comment != "" {
+		attr = fmt.Sprintf("%s %s", attr, quote(c.Comment))
+	}
+	return attr
+}
+
+// relationship return erDiagram relationship line.
+// Child side is "zero or one" when the columns are unique, otherwise "zero or more".
+// Parent side is "zero or one" when the columns are nullable, otherwise "exactly one".
+// Virtual relations are drawn as non-identifying ( dashed ) relationships.
+func relationship(r *schema.Relation) string {
+	child := "}o"
+	columns := []string{}
+	for _, c := range r.Columns {
+		columns = append(columns, c.Name)
+	}
+	if r.Table.IsUnique(columns) {
+		child = "|o"
+	}
+	parent := "||"
+	for _, c := range r.Columns {
+		if c.Nullable {
+			parent = "o|"
+		}
+	}
+	line := "--"
+	if r.Virtual {
+		line = ".."
+	}
+	label := r.Def
+	if label == "" {
+		label = strings.Join(columns, ", ")
+	}
+	return fmt.Sprintf("%s %s%s%s %s : %s", entity(r.Table.Name), child, line, parent, entity(r.ParentTable.Name), quote(label))
+}
+
+var (
+	invalidIdentifierRe = regexp.MustCompile(`[^A-Za-z0-9_\-\[\]\(\)]+`)
+	identifierStartRe   = regexp.MustCompile(`^[A-Za-z_]`)
+)
+
+// identifier return attribute type/name usable in erDiagram
+func identifier(v string) string {
+	v = strings.Trim(invalidIdentifierRe.ReplaceAllString(v, "_"), "_")
+	if v == "" || !identifierStartRe.MatchString(v) {
+		v = fmt.Sprintf("_%s", v)
+	}
+	return v
+}
+
+func entity(name string) string {
+	return quote(name)
+}
+
+func quote(v string) string {
+	r := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", `"`, "'")
+	return fmt.Sprintf(`"%s"`, r.Replace(v))
+}
+
+func contains(rs []*schema.Relation, e *schema.Relation) bool {
+	for _, r := range rs {
+		if e == r {
+			return true
+		}
+	}
+	return false
+}
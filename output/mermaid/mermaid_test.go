@@ -0,0 +1,172 @@
+package mermaid
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestOutputSchema(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "out_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewMermaid(c)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "mermaid_test_schema.mmd.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputTable(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.LoadConfigFile(filepath.Join(testdataDir(), "out_test_tbls.yml"))
+	if err != nil {
+		t.Error(err)
+	}
+	err = c.MergeAdditionalData(s)
+	if err != nil {
+		t.Error(err)
+	}
+	ta := s.Tables[0]
+
+	o := NewMermaid(c)
+	buf := &bytes.Buffer{}
+	_ = o.OutputTable(buf, ta)
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "mermaid_test_a.mmd.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputSchemaExternal(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[0].External = true
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewMermaid(c)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := buf.String(), "  %% external table\n  \"a\" {"; !strings.Contains(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestRelationship(t *testing.T) {
+	s := newTestSchema()
+	r := s.Relations[0]
+	expected := `"a" }o--|| "b" : "a"`
+	if actual := relationship(r); actual != expected {
+		t.Errorf("actual %v\nwant %v", actual, expected)
+	}
+
+	r.Columns[0].Nullable = true
+	r.Virtual = true
+	r.Def = "a->b"
+	r.Table.Constraints = []*schema.Constraint{
+		&schema.Constraint{
+			Name:    "a_unique",
+			Type:    "UNIQUE",
+			Columns: []string{"a"},
+		},
+	}
+	expected = `"a" |o..o| "b" : "a->b"`
+	if actual := relationship(r); actual != expected {
+		t.Errorf("actual %v\nwant %v", actual, expected)
+	}
+}
+
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
+
+func newTestSchema() *schema.Schema {
+	ca := &schema.Column{
+		Name:    "a",
+		Comment: "column a",
+	}
+	cb := &schema.Column{
+		Name:    "b",
+		Comment: "column b",
+	}
+
+	ta := &schema.Table{
+		Name:    "a",
+		Comment: "table a",
+		Columns: []*schema.Column{
+			ca,
+			&schema.Column{
+				Name:    "a2",
+				Comment: "column a2",
+			},
+		},
+	}
+	tb := &schema.Table{
+		Name:    "b",
+		Comment: "table b",
+		Columns: []*schema.Column{
+			cb,
+			&schema.Column{
+				Name:    "b2",
+				Comment: "column b2",
+			},
+		},
+	}
+	r := &schema.Relation{
+		Table:         ta,
+		Columns:       []*schema.Column{ca},
+		ParentTable:   tb,
+		ParentColumns: []*schema.Column{cb},
+	}
+	ca.ParentRelations = []*schema.Relation{r}
+	cb.ChildRelations = []*schema.Relation{r}
+
+	s := &schema.Schema{
+		Name: "testschema",
+		Tables: []*schema.Table{
+			ta,
+			tb,
+		},
+		Relations: []*schema.Relation{
+			r,
+		},
+		Driver: &schema.Driver{
+			Name:            "testdriver",
+			DatabaseVersion: "1.0.0",
+		},
+	}
+	return s
+}
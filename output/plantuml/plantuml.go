@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"strings"
-	"text/template"
 
 	"github.com/gobuffalo/packr/v2"
 	"github.com/Melsoft-Games/tbls/config"
@@ -44,11 +43,10 @@ func (p *PlantUML) OutputSchema(wr io.Writer, s *schema.Schema) error {
 		}
 	}
 
-	ts, err := p.box.FindString("schema.puml.tmpl")
+	tmpl, err := config.LoadTemplate("schema.puml.tmpl", p.config.Templates.PUML.Schema, p.box.FindString, templateFuncs)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	tmpl := template.Must(template.New(s.Name).Funcs(templateFuncs).Parse(ts))
 	err = tmpl.Execute(wr, map[string]interface{}{
 		"Schema":      s,
 		"showComment": p.config.ER.Comment,
@@ -98,11 +96,10 @@ func (p *PlantUML) OutputTable(wr io.Writer, t *schema.Table) error {
 		}
 	}
 
-	ts, err := p.box.FindString("table.puml.tmpl")
+	tmpl, err := config.LoadTemplate("table.puml.tmpl", p.config.Templates.PUML.Table, p.box.FindString, templateFuncs)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	tmpl := template.Must(template.New(t.Name).Funcs(templateFuncs).Parse(ts))
 	err = tmpl.Execute(wr, map[string]interface{}{
 		"Table":       t,
 		"Tables":      tables,
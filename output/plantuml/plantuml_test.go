@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Melsoft-Games/tbls/config"
@@ -64,6 +65,24 @@ func TestOutputTable(t *testing.T) {
 	}
 }
 
+func TestOutputSchemaExternal(t *testing.T) {
+	s := newTestSchema()
+	s.Tables[0].External = true
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewPlantUML(c)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := buf.String(), `entity "a" as "a\n[external]"`; !strings.Contains(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
 func testdataDir() string {
 	wd, _ := os.Getwd()
 	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
@@ -0,0 +1,188 @@
+package proto
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/typemap"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+const timestampType = "google.protobuf.Timestamp"
+
+// Proto struct
+type Proto struct {
+	config *config.Config
+	driver *schema.Driver
+}
+
+// NewProto return Proto.
+// driver is used to map column types of the database.
+func NewProto(c *config.Config, d *schema.Driver) *Proto {
+	return &Proto{
+		config: c,
+		driver: d,
+	}
+}
+
+// OutputSchema output proto3 messages for all tables except external tables.
+func (p *Proto) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	driver := p.driver
+	if s.Driver != nil {
+		driver = s.Driver
+	}
+	tables := []*schema.Table{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		tables = append(tables, t)
+	}
+	return p.output(wr, driverName(driver), tables)
+}
+
+// OutputTable output proto3 message of table.
+func (p *Proto) OutputTable(wr io.Writer, t *schema.Table) error {
+	return p.output(wr, driverName(p.driver), []*schema.Table{t})
+}
+
+func (p *Proto) output(wr io.Writer, driver string, tables []*schema.Table) error {
+	g := &generator{}
+	messages := []string{}
+	for _, t := range tables {
+		messages = append(messages, g.message(messageName(t.Name), t.Comment, typemap.Fields(driver, t), ""))
+	}
+	header := []string{`syntax = "proto3";`}
+	if g.timestamp {
+		header = append(header, "", `import "google/protobuf/timestamp.proto";`)
+	}
+	_, err := fmt.Fprintf(wr, "%s\n\n%s", strings.Join(header, "\n"), strings.Join(messages, "\n"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+type generator struct {
+	timestamp bool
+}
+
+func (g *generator) message(name, comment string, fields []*typemap.Field, indent string) string {
+	b := new(strings.Builder)
+	b.WriteString(docComment(comment, indent))
+	fmt.Fprintf(b, "%smessage %s {\n", indent, name)
+	for i, f := range fields {
+		fieldName := identifier(f.Name)
+		typ := ""
+		switch f.Type.Kind {
+		case typemap.Enum:
+			typ = messageName(f.Name)
+			b.WriteString(enum(typ, f.Type.Symbols, indent+"  "))
+		case typemap.Record:
+			typ = messageName(f.Name)
+			b.WriteString(g.message(typ, "", f.Fields, indent+"  "))
+		default:
+			typ = g.scalar(f.Type)
+		}
+		label := ""
+		switch {
+		case f.Type.Repeated:
+			label = "repeated "
+		case f.Nullable && f.Type.Kind != typemap.Record && typ != timestampType:
+			label = "optional "
+		}
+		b.WriteString(docComment(f.Column.Comment, indent+"  "))
+		fmt.Fprintf(b, "%s  %s%s %s = %d;\n", indent, label, typ, fieldName, i+1)
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+	return b.String()
+}
+
+func (g *generator) scalar(t typemap.Type) string {
+	switch t.Kind {
+	case typemap.Boolean:
+		return "bool"
+	case typemap.Int32:
+		return "int32"
+	case typemap.Int64:
+		return "int64"
+	case typemap.Float:
+		return "float"
+	case typemap.Double:
+		return "double"
+	case typemap.Bytes:
+		return "bytes"
+	case typemap.Timestamp:
+		g.timestamp = true
+		return timestampType
+	}
+	return "string"
+}
+
+// enum return proto3 enum. The first value must be zero, so `*_UNSPECIFIED` is added.
+func enum(name string, symbols []string, indent string) string {
+	prefix := strings.ToUpper(snake(name))
+	lines := []string{
+		fmt.Sprintf("%senum %s {", indent, name),
+		fmt.Sprintf("%s  %s_UNSPECIFIED = 0;", indent, prefix),
+	}
+	for i, s := range symbols {
+		lines = append(lines, fmt.Sprintf("%s  %s_%s = %d;", indent, prefix, strings.ToUpper(identifier(s)), i+1))
+	}
+	lines = append(lines, fmt.Sprintf("%s}\n", indent))
+	return strings.Join(lines, "\n")
+}
+
+func docComment(comment, indent string) string {
+	if comment == "" {
+		return ""
+	}
+	lines := []string{}
+	for _, l := range strings.Split(strings.Replace(comment, "\r\n", "\n", -1), "\n") {
+		lines = append(lines, strings.TrimRight(fmt.Sprintf("%s// %s", indent, l), " "))
+	}
+	return fmt.Sprintf("%s\n", strings.Join(lines, "\n"))
+}
+
+var (
+	invalidRe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+	wordRe    = regexp.MustCompile(`[A-Za-z0-9]+`)
+	upperRe   = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+)
+
+// identifier return proto3 identifier. Invalid characters are replaced with `_`.
+func identifier(v string) string {
+	v = invalidRe.ReplaceAllString(v, "_")
+	if v == "" || (v[0] >= '0' && v[0] <= '9') {
+		v = fmt.Sprintf("_%s", v)
+	}
+	return v
+}
+
+// messageName return CamelCase name. e.g. `audit.user_logs` -> `AuditUserLogs`
+func messageName(v string) string {
+	words := wordRe.FindAllString(v, -1)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	name := strings.Join(words, "")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = fmt.Sprintf("T%s", name)
+	}
+	return name
+}
+
+func snake(v string) string {
+	return upperRe.ReplaceAllString(v, "${1}_${2}")
+}
+
+func driverName(d *schema.Driver) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
@@ -0,0 +1,95 @@
+package proto
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+func TestOutputTable(t *testing.T) {
+	s := newTestSchema()
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewProto(c, s.Driver)
+	buf := &bytes.Buffer{}
+	err = o.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), "proto_test_events.proto.golden"))
+	actual := buf.String()
+	if actual != string(expected) {
+		t.Errorf("actual %v\nwant %v", actual, string(expected))
+	}
+}
+
+func TestOutputSchemaExternal(t *testing.T) {
+	s := newTestSchema()
+	s.Tables = append(s.Tables, &schema.Table{
+		Name:     "dataset.users",
+		Type:     "TABLE",
+		External: true,
+		Columns:  []*schema.Column{&schema.Column{Name: "id", Type: "INTEGER"}},
+	})
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewProto(c, s.Driver)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	if got := buf.String(); strings.Contains(got, "Users") {
+		t.Errorf("external table is output: %v", got)
+	}
+}
+
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
+
+// newTestSchema return the table that covers repeated ( ARRAY<X> and BigQuery REPEATED mode ),
+// nested ( RECORD in RECORD ) and unknown ( empty ) column types
+func newTestSchema() *schema.Schema {
+	t := &schema.Table{
+		Name:    "dataset.events",
+		Type:    "TABLE",
+		Comment: "Events table",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "event_id", Type: "STRING", Comment: "event id"},
+			&schema.Column{Name: "ts", Type: "TIMESTAMP", Nullable: true},
+			&schema.Column{Name: "amount", Type: "NUMERIC", Nullable: true},
+			&schema.Column{Name: "user", Type: "RECORD", Nullable: true, Comment: "event user"},
+			&schema.Column{Name: "user.id", Type: "INTEGER"},
+			&schema.Column{Name: "user.tags", Type: "ARRAY<STRING>", Nullable: true},
+			&schema.Column{Name: "user.address", Type: "RECORD", Nullable: true},
+			&schema.Column{Name: "user.address.city", Type: "STRING", Nullable: true},
+			&schema.Column{Name: "items", Type: "RECORD", Nullable: true, Repeated: true},
+			&schema.Column{Name: "items.sku", Type: "STRING"},
+			&schema.Column{Name: "items.qty", Type: "INT64", Nullable: true},
+			&schema.Column{Name: "labels", Type: "STRING", Nullable: true, Repeated: true},
+			&schema.Column{Name: "location", Type: "GEOGRAPHY", Nullable: true},
+			&schema.Column{Name: "raw", Type: "", Nullable: true},
+		},
+	}
+	return &schema.Schema{
+		Name:   "testschema",
+		Tables: []*schema.Table{t},
+		Driver: &schema.Driver{
+			Name:            "bigquery",
+			DatabaseVersion: "",
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package typemap
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+// Kind is database independent type of column
+type Kind int
+
+// Kinds
+const (
+	Unknown Kind = iota
+	Boolean
+	Int32
+	Int64
+	Float
+	Double
+	Decimal
+	String
+	Bytes
+	Date
+	Time
+	Timestamp
+	JSON
+	UUID
+	Enum
+	Record
+)
+
+// Type is database independent type of column
+type Type struct {
+	Kind      Kind
+	Precision int
+	Scale     int
+	Symbols   []string
+	Repeated  bool
+}
+
+// Field is column ( or nested column of RECORD ) with database independent type
+type Field struct {
+	Name     string
+	Column   *schema.Column
+	Type     Type
+	Nullable bool
+	Fields   []*Field
+}
+
+const (
+	defaultPrecision = 38
+	defaultScale     = 9
+)
+
+var (
+	arrayRe   = regexp.MustCompile(`^(?i)ARRAY<(.+)>$`)
+	argsRe    = regexp.MustCompile(`\(([^()]*)\)`)
+	enumRe    = regexp.MustCompile(`^(?i)enum\((.*)\)$`)
+	symbolRe  = regexp.MustCompile(`'((?:[^']|'')*)'`)
+	unsignRe  = regexp.MustCompile(`(?i)\bunsigned\b`)
+	tinyintRe = regexp.MustCompile(`^(?i)tinyint\(1\)`)
+)
+
+// Fields return fields of the table.
+// Flattened nested columns ( e.g. BigQuery RECORD `a`, `a.b` ) are restored as nested fields.
+func Fields(driver string, t *schema.Table) []*Field {
+	fields := []*Field{}
+	records := map[string]*Field{}
+	for _, c := range t.Columns {
+		f := &Field{
+			Name:     c.Name,
+			Column:   c,
+			Type:     Map(driver, c.Type),
+			Nullable: c.Nullable,
+		}
+		if c.Repeated {
+			f.Type.Repeated = true
+		}
+		parent := (*Field)(nil)
+		for i := strings.LastIndex(c.Name, "."); i > 0; i = strings.LastIndex(c.Name[:i], ".") {
+			if p, ok := records[c.Name[:i]]; ok {
+				parent = p
+				f.Name = c.Name[i+1:]
+				break
+			}
+		}
+		if f.Type.Kind == Record {
+			records[c.Name] = f
+		}
+		if parent != nil {
+			parent.Fields = append(parent.Fields, f)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// Map return database independent type of column type
+func Map(driver, typ string) Type {
+	t := strings.TrimSpace(typ)
+	if m := arrayRe.FindStringSubmatch(t); m != nil {
+		e := Map(driver, m[1])
+		e.Repeated = true
+		return e
+	}
+	if strings.HasSuffix(t, "[]") {
+		e := Map(driver, strings.TrimSuffix(t, "[]"))
+		e.Repeated = true
+		return e
+	}
+	if m := enumRe.FindStringSubmatch(t); m != nil {
+		symbols := []string{}
+		for _, s := range symbolRe.FindAllStringSubmatch(m[1], -1) {
+			symbols = append(symbols, strings.Replace(s[1], "''", "'", -1))
+		}
+		return Type{Kind: Enum, Symbols: symbols}
+	}
+	if driver == "mysql" && tinyintRe.MatchString(t) {
+		return Type{Kind: Boolean}
+	}
+	unsigned := unsignRe.MatchString(t)
+	args := []int{}
+	if m := argsRe.FindStringSubmatch(t); m != nil {
+		for _, a := range strings.Split(m[1], ",") {
+			if v, err := strconv.Atoi(strings.TrimSpace(a)); err == nil {
+				args = append(args, v)
+			}
+		}
+	}
+	base := strings.ToLower(strings.TrimSpace(argsRe.ReplaceAllString(unsignRe.ReplaceAllString(t, ""), "")))
+
+	switch base {
+	case "bool", "boolean", "bit":
+		return Type{Kind: Boolean}
+	case "tinyint", "smallint", "mediumint", "int2", "smallserial", "serial", "serial4":
+		return Type{Kind: Int32}
+	case "int", "integer", "int4":
+		if driver == "bigquery" || unsigned {
+			return Type{Kind: Int64}
+		}
+		return Type{Kind: Int32}
+	case "bigint", "int8", "int64", "bigserial", "serial8":
+		return Type{Kind: Int64}
+	case "real", "float4":
+		return Type{Kind: Float}
+	case "float":
+		if driver == "bigquery" {
+			return Type{Kind: Double}
+		}
+		return Type{Kind: Float}
+	case "double", "double precision", "float8", "float64":
+		return Type{Kind: Double}
+	case "decimal", "numeric", "dec", "fixed", "bignumeric", "bigdecimal", "money":
+		d := Type{Kind: Decimal, Precision: defaultPrecision, Scale: defaultScale}
+		if base == "bignumeric" || base == "bigdecimal" {
+			d.Precision = 76
+			d.Scale = 38
+		}
+		if len(args) > 0 {
+			d.Precision = args[0]
+			d.Scale = 0
+		}
+		if len(args) > 1 {
+			d.Scale = args[1]
+		}
+		return d
+	case "date":
+		return Type{Kind: Date}
+	case "time", "time without time zone", "time with time zone", "timetz":
+		return Type{Kind: Time}
+	case "datetime", "timestamp", "timestamp without time zone", "timestamp with time zone", "timestamptz":
+		return Type{Kind: Timestamp}
+	case "json", "jsonb":
+		return Type{Kind: JSON}
+	case "uuid":
+		return Type{Kind: UUID}
+	case "bytea", "bytes", "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob":
+		return Type{Kind: Bytes}
+	case "record", "struct":
+		return Type{Kind: Record}
+	case "array":
+		return Type{Kind: String, Repeated: true}
+	case "":
+		return Type{Kind: Unknown}
+	}
+	return Type{Kind: String}
+}
@@ -0,0 +1,80 @@
+package typemap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+var mapTests = []struct {
+	driver string
+	typ    string
+	want   Type
+}{
+	{"postgres", "integer", Type{Kind: Int32}},
+	{"postgres", "bigint", Type{Kind: Int64}},
+	{"postgres", "varchar(255)", Type{Kind: String}},
+	{"postgres", "numeric(10,2)", Type{Kind: Decimal, Precision: 10, Scale: 2}},
+	{"postgres", "timestamp without time zone", Type{Kind: Timestamp}},
+	{"postgres", "jsonb", Type{Kind: JSON}},
+	{"postgres", "uuid", Type{Kind: UUID}},
+	{"postgres", "bytea", Type{Kind: Bytes}},
+	{"postgres", "text[]", Type{Kind: String, Repeated: true}},
+	{"mysql", "int(11)", Type{Kind: Int32}},
+	{"mysql", "int(10) unsigned", Type{Kind: Int64}},
+	{"mysql", "tinyint(1)", Type{Kind: Boolean}},
+	{"mysql", "float", Type{Kind: Float}},
+	{"mysql", "datetime", Type{Kind: Timestamp}},
+	{"mysql", "enum('public','private')", Type{Kind: Enum, Symbols: []string{"public", "private"}}},
+	{"bigquery", "INTEGER", Type{Kind: Int64}},
+	{"bigquery", "FLOAT", Type{Kind: Double}},
+	{"bigquery", "NUMERIC", Type{Kind: Decimal, Precision: 38, Scale: 9}},
+	{"bigquery", "RECORD", Type{Kind: Record}},
+	{"bigquery", "ARRAY<RECORD>", Type{Kind: Record, Repeated: true}},
+	{"bigquery", "GEOGRAPHY", Type{Kind: String}},
+	{"bigquery", "ARRAY<NUMERIC(10, 2)>", Type{Kind: Decimal, Precision: 10, Scale: 2, Repeated: true}},
+	{"postgres", "integer[]", Type{Kind: Int32, Repeated: true}},
+	{"postgres", "", Type{Kind: Unknown}},
+}
+
+func TestMap(t *testing.T) {
+	for _, tt := range mapTests {
+		got := Map(tt.driver, tt.typ)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: got %v\nwant %v", tt.driver, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	table := &schema.Table{
+		Name: "events",
+		Columns: []*schema.Column{
+			&schema.Column{Name: "id", Type: "STRING"},
+			&schema.Column{Name: "user", Type: "RECORD"},
+			&schema.Column{Name: "user.address", Type: "RECORD"},
+			&schema.Column{Name: "user.address.city", Type: "STRING"},
+			&schema.Column{Name: "user.name", Type: "STRING"},
+			&schema.Column{Name: "a.b", Type: "STRING"},
+			&schema.Column{Name: "tags", Type: "STRING", Repeated: true},
+		},
+	}
+	fields := Fields("bigquery", table)
+	if len(fields) != 4 {
+		t.Fatalf("got %v\nwant %v", len(fields), 4)
+	}
+	user := fields[1]
+	if len(user.Fields) != 2 || user.Fields[0].Name != "address" || user.Fields[1].Name != "name" {
+		t.Errorf("got %v", user.Fields)
+	}
+	if city := user.Fields[0].Fields[0]; city.Name != "city" {
+		t.Errorf("got %v\nwant %v", city.Name, "city")
+	}
+	if fields[2].Name != "a.b" {
+		t.Errorf("got %v\nwant %v", fields[2].Name, "a.b")
+	}
+	if want := (Type{Kind: String, Repeated: true}); !reflect.DeepEqual(fields[3].Type, want) {
+		t.Errorf("got %v\nwant %v", fields[3].Type, want)
+	}
+}
@@ -0,0 +1,207 @@
+package typescript
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/typemap"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// TypeScript struct
+type TypeScript struct {
+	config *config.Config
+	driver *schema.Driver
+}
+
+// NewTypeScript return TypeScript.
+// driver is used to map column types of the database.
+func NewTypeScript(c *config.Config, d *schema.Driver) *TypeScript {
+	return &TypeScript{
+		config: c,
+		driver: d,
+	}
+}
+
+// OutputSchema output TypeScript interfaces for all tables except external tables.
+func (ts *TypeScript) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	driver := ts.driver
+	if s.Driver != nil {
+		driver = s.Driver
+	}
+	tables := []*schema.Table{}
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		tables = append(tables, t)
+	}
+	return ts.output(wr, driverName(driver), tables)
+}
+
+// OutputTable output TypeScript interface for table.
+func (ts *TypeScript) OutputTable(wr io.Writer, t *schema.Table) error {
+	return ts.output(wr, driverName(ts.driver), []*schema.Table{t})
+}
+
+func (ts *TypeScript) output(wr io.Writer, driver string, tables []*schema.Table) error {
+	module := ts.config.Gen.TypeScript.Module
+	indent := ""
+	if module != "" {
+		indent = "  "
+	}
+	gen := &generator{
+		indent: indent,
+		names:  map[string]bool{},
+	}
+	// reserve the table interface names first so that nested types get the suffix on collision
+	names := []string{}
+	for _, t := range tables {
+		names = append(names, uniqueName(gen.names, typeName(t.Name)))
+	}
+
+	body := new(bytes.Buffer)
+	for i, t := range tables {
+		gen.writeInterface(body, names[i], t.Comment, typemap.Fields(driver, t))
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("// Code generated by tbls. DO NOT EDIT.\n")
+	if module != "" {
+		fmt.Fprintf(buf, "\ndeclare module %s {", quote(module))
+		_, _ = body.WriteTo(buf)
+		buf.WriteString("}\n")
+	} else {
+		_, _ = body.WriteTo(buf)
+	}
+	_, err := buf.WriteTo(wr)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+type generator struct {
+	indent string
+	names  map[string]bool
+}
+
+// writeInterface write interface. Nested types ( enum and RECORD ) are written after the interface.
+func (g *generator) writeInterface(wr io.Writer, name, comment string, fields []*typemap.Field) {
+	indent := g.indent
+	nested := new(bytes.Buffer)
+	fmt.Fprintf(wr, "\n%s%sexport interface %s {\n", docComment(comment, indent), indent, name)
+	for _, f := range fields {
+		typ := ""
+		switch f.Type.Kind {
+		case typemap.Enum:
+			typ = uniqueName(g.names, name+typeName(f.Name))
+			symbols := []string{}
+			for _, s := range f.Type.Symbols {
+				symbols = append(symbols, quote(s))
+			}
+			fmt.Fprintf(nested, "\n%s%sexport type %s = %s;\n", docComment(fmt.Sprintf("enum of %s", f.Column.Name), indent), indent, typ, strings.Join(symbols, " | "))
+		case typemap.Record:
+			typ = uniqueName(g.names, name+typeName(f.Name))
+			g.writeInterface(nested, typ, "", f.Fields)
+		default:
+			typ = scalar(f.Type)
+		}
+		if f.Type.Repeated {
+			typ = fmt.Sprintf("%s[]", typ)
+		} else if f.Nullable {
+			typ = fmt.Sprintf("%s | null", typ)
+		}
+		fmt.Fprintf(wr, "%s%s  %s: %s;\n", docComment(f.Column.Comment, indent+"  "), indent, propertyName(f.Name), typ)
+	}
+	fmt.Fprintf(wr, "%s}\n", indent)
+	_, _ = nested.WriteTo(wr)
+}
+
+func scalar(t typemap.Type) string {
+	switch t.Kind {
+	case typemap.Boolean:
+		return "boolean"
+	case typemap.Int32, typemap.Int64, typemap.Float, typemap.Double:
+		return "number"
+	case typemap.Bytes:
+		return "Uint8Array"
+	case typemap.Date, typemap.Timestamp:
+		return "Date"
+	case typemap.JSON, typemap.Unknown:
+		return "unknown"
+	}
+	return "string"
+}
+
+var (
+	wordRe       = regexp.MustCompile(`[A-Za-z0-9]+`)
+	identifierRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+// typeName return PascalCase name. e.g. `post_comments` -> `PostComments`
+func typeName(v string) string {
+	words := wordRe.FindAllString(v, -1)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	name := strings.Join(words, "")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = fmt.Sprintf("T%s", name)
+	}
+	return name
+}
+
+// uniqueName return name that is not in used, and mark it as used. e.g. `UserLog` -> `UserLog2`
+func uniqueName(used map[string]bool, name string) string {
+	n := name
+	for i := 2; used[n]; i++ {
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+	used[n] = true
+	return n
+}
+
+func propertyName(v string) string {
+	if identifierRe.MatchString(v) {
+		return v
+	}
+	return quote(v)
+}
+
+func quote(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return fmt.Sprintf("'%s'", r.Replace(v))
+}
+
+// docComment return JSDoc comment
+func docComment(comment, indent string) string {
+	if comment == "" {
+		return ""
+	}
+	comment = strings.Replace(strings.Replace(comment, "\r\n", "\n", -1), "*/", "*\\/", -1)
+	lines := strings.Split(comment, "\n")
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, lines[0])
+	}
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, l := range lines {
+		fmt.Fprintf(b, "%s", strings.TrimRight(fmt.Sprintf("%s * %s", indent, l), " "))
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+	return b.String()
+}
+
+func driverName(d *schema.Driver) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
@@ -0,0 +1,182 @@
+package typescript
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/schema"
+)
+
+var tests = []struct {
+	driver string
+	golden string
+}{
+	{"mysql", "typescript_test_posts.ts.golden"},
+	{"bigquery", "typescript_test_events.ts.golden"},
+}
+
+func TestOutputTable(t *testing.T) {
+	for _, tt := range tests {
+		s := newTestSchema(tt.driver)
+		c, err := config.NewConfig()
+		if err != nil {
+			t.Error(err)
+		}
+		o := NewTypeScript(c, s.Driver)
+		buf := &bytes.Buffer{}
+		err = o.OutputTable(buf, s.Tables[0])
+		if err != nil {
+			t.Error(err)
+		}
+		expected, _ := ioutil.ReadFile(filepath.Join(testdataDir(), tt.golden))
+		actual := buf.String()
+		if actual != string(expected) {
+			t.Errorf("actual %v\nwant %v", actual, string(expected))
+		}
+	}
+}
+
+func TestOutputSchemaExternal(t *testing.T) {
+	s := newTestSchema("mysql")
+	s.Tables = append(s.Tables, &schema.Table{
+		Name:     "users",
+		Type:     "BASE TABLE",
+		External: true,
+		Columns:  []*schema.Column{&schema.Column{Name: "id", Type: "bigint(20)"}},
+	})
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewTypeScript(c, s.Driver)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	if got := buf.String(); strings.Contains(got, "Users") {
+		t.Errorf("external table is output: %v", got)
+	}
+}
+
+func TestOutputSchemaNameCollision(t *testing.T) {
+	s := &schema.Schema{
+		Name: "testschema",
+		Tables: []*schema.Table{
+			&schema.Table{Name: "orders", Type: "TABLE", Columns: []*schema.Column{
+				&schema.Column{Name: "items", Type: "RECORD", Nullable: true},
+				&schema.Column{Name: "items.sku", Type: "STRING"},
+			}},
+			&schema.Table{Name: "orders_items", Type: "TABLE", Columns: []*schema.Column{&schema.Column{Name: "sku", Type: "STRING"}}},
+			&schema.Table{Name: "user_log", Type: "TABLE", Columns: []*schema.Column{
+				&schema.Column{Name: "user_id", Type: "INTEGER"},
+				&schema.Column{Name: "user.id", Type: "INTEGER"},
+			}},
+			&schema.Table{Name: "user.log", Type: "TABLE", Columns: []*schema.Column{&schema.Column{Name: "id", Type: "INTEGER"}}},
+		},
+		Driver: &schema.Driver{Name: "bigquery"},
+	}
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	o := NewTypeScript(c, s.Driver)
+	buf := &bytes.Buffer{}
+	err = o.OutputSchema(buf, s)
+	if err != nil {
+		t.Error(err)
+	}
+	actual := buf.String()
+	for _, want := range []string{
+		"export interface Orders {",
+		"items: OrdersItems2 | null;",
+		"export interface OrdersItems2 {",
+		"export interface OrdersItems {",
+		"export interface UserLog {",
+		"export interface UserLog2 {",
+	} {
+		if !strings.Contains(actual, want) {
+			t.Errorf("got %v\nwant contains %v", actual, want)
+		}
+	}
+}
+
+func testdataDir() string {
+	wd, _ := os.Getwd()
+	dir, _ := filepath.Abs(filepath.Join(filepath.Dir(filepath.Dir(wd)), "testdata"))
+	return dir
+}
+
+func newTestSchema(driver string) *schema.Schema {
+	var t *schema.Table
+	switch driver {
+	case "bigquery":
+		t = &schema.Table{
+			Name:    "dataset.events",
+			Type:    "TABLE",
+			Comment: "Events table",
+			Columns: []*schema.Column{
+				&schema.Column{Name: "event_id", Type: "STRING", Comment: "event id"},
+				&schema.Column{Name: "ts", Type: "TIMESTAMP", Nullable: true},
+				&schema.Column{Name: "amount", Type: "NUMERIC", Nullable: true},
+				&schema.Column{Name: "user", Type: "RECORD", Nullable: true, Comment: "event user"},
+				&schema.Column{Name: "user.id", Type: "INTEGER"},
+				&schema.Column{Name: "user.tags", Type: "ARRAY<STRING>", Nullable: true},
+				&schema.Column{Name: "items", Type: "ARRAY<RECORD>", Nullable: true},
+				&schema.Column{Name: "items.sku", Type: "STRING"},
+				&schema.Column{Name: "items.qty", Type: "INT64", Nullable: true},
+			},
+		}
+	default:
+		t = &schema.Table{
+			Name:    "posts",
+			Type:    "BASE TABLE",
+			Comment: "Posts table\nMulti-line",
+			Columns: []*schema.Column{
+				&schema.Column{Name: "id", Type: "bigint(20)"},
+				&schema.Column{Name: "user_id", Type: "int(11)"},
+				&schema.Column{Name: "title", Type: "varchar(255)", Comment: "post title"},
+				&schema.Column{Name: "body", Type: "text", Nullable: true},
+				&schema.Column{Name: "post_type", Type: "enum('public','private','draft')", Comment: "public/private/draft"},
+				&schema.Column{Name: "published", Type: "tinyint(1)", Nullable: true},
+				&schema.Column{Name: "meta", Type: "json", Nullable: true},
+				&schema.Column{Name: "created", Type: "datetime"},
+				&schema.Column{Name: "updated", Type: "datetime", Nullable: true},
+			},
+		}
+	}
+	return &schema.Schema{
+		Name:   "testschema",
+		Tables: []*schema.Table{t},
+		Driver: &schema.Driver{
+			Name:            driver,
+			DatabaseVersion: "",
+		},
+	}
+}
+
+func TestModule(t *testing.T) {
+	s := newTestSchema("mysql")
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Error(err)
+	}
+	c.Gen.TypeScript.Module = "@example/models"
+	o := NewTypeScript(c, s.Driver)
+	buf := &bytes.Buffer{}
+	err = o.OutputTable(buf, s.Tables[0])
+	if err != nil {
+		t.Error(err)
+	}
+	actual := buf.String()
+	for _, want := range []string{"declare module '@example/models' {\n", "\n  export interface Posts {\n", "\n    title: string;\n", "\n}\n"} {
+		if !strings.Contains(actual, want) {
+			t.Errorf("got %v\nwant contains %v", actual, want)
+		}
+	}
+}
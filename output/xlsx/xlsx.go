@@ -8,13 +8,27 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/output/columns"
 	"github.com/Melsoft-Games/tbls/schema"
 	"github.com/loadoff/excl"
 	"github.com/pkg/errors"
 )
 
 // Xlsx struct
-type Xlsx struct{}
+type Xlsx struct {
+	config *config.Config
+	driver *schema.Driver
+}
+
+// NewXlsx return Xlsx.
+// driver is used to choose the default columns of table sheet.
+func NewXlsx(c *config.Config, d *schema.Driver) *Xlsx {
+	return &Xlsx{
+		config: c,
+		driver: d,
+	}
+}
 
 // OutputSchema output Xlsx format for full relation.
 func (x *Xlsx) OutputSchema(wr io.Writer, s *schema.Schema) error {
@@ -27,7 +41,10 @@ func (x *Xlsx) OutputSchema(wr io.Writer, s *schema.Schema) error {
 		return err
 	}
 	for _, t := range s.Tables {
-		err = createTableSheet(w, t)
+		if t.External {
+			continue
+		}
+		err = x.createTableSheet(w, t)
 		if err != nil {
 			return err
 		}
@@ -56,7 +73,7 @@ func (x *Xlsx) OutputTable(wr io.Writer, t *schema.Table) error {
 	if err != nil {
 		return err
 	}
-	err = createTableSheet(w, t)
+	err = x.createTableSheet(w, t)
 	if err != nil {
 		return err
 	}
@@ -91,20 +108,54 @@ func createSchemaSheet(w *excl.Workbook, s *schema.Schema) error {
 	setString(sheet, 1, 1, s.Name).SetFont(excl.Font{Bold: true})
 
 	setString(sheet, 3, 1, "Tables").SetFont(excl.Font{Bold: true})
-	setHeader(sheet, 4, []string{"Name", "Columns", "Comment", "Type"})
+	header := []string{"Name", "Columns", "Comment", "Type"}
+	hasLabels := false
+	for _, t := range s.Tables {
+		if len(t.Labels) > 0 {
+			hasLabels = true
+		}
+	}
+	if hasLabels {
+		header = append(header, "Labels")
+	}
+	setHeader(sheet, 4, header)
 	n := 5
-	for i, t := range s.Tables {
-		setStringWithBorder(sheet, n+i, 1, t.Name)
-		setNumberWithBorder(sheet, n+i, 2, len(t.Columns))
-		setStringWithBorder(sheet, n+i, 3, t.Comment)
-		setStringWithBorder(sheet, n+i, 4, t.Type)
+	for _, t := range s.Tables {
+		if t.External {
+			continue
+		}
+		setStringWithBorder(sheet, n, 1, t.Name)
+		setNumberWithBorder(sheet, n, 2, len(t.Columns))
+		setStringWithBorder(sheet, n, 3, t.Comment)
+		setStringWithBorder(sheet, n, 4, t.Type)
+		if hasLabels {
+			setStringWithBorder(sheet, n, 5, joinLabels(t.Labels))
+		}
+		n++
 	}
 
 	return nil
 }
 
-func createTableSheet(w *excl.Workbook, t *schema.Table) error {
-	sheetName := t.Name
+func (x *Xlsx) createTableSheet(w *excl.Workbook, t *schema.Table) error {
+	cols, err := columns.Columns(x.config, x.driver)
+	if err != nil {
+		return err
+	}
+	ct, err := columns.Rows(cols, t, columns.HideEmpty(x.config, x.driver), columns.Format{
+		Link: func(t *schema.Table) string {
+			return t.Name
+		},
+		Label: func(l *schema.Label) string {
+			return l.String()
+		},
+		Sep: "\n",
+	})
+	if err != nil {
+		return err
+	}
+
+	sheetName := t.FileName()
 	if utf8.RuneCountInString(sheetName) > 31 { // MS Excel assumes a maximum length of 31 characters for sheet name
 		r := []rune(sheetName)
 		sheetName = string(r[0:31])
@@ -117,26 +168,17 @@ func createTableSheet(w *excl.Workbook, t *schema.Table) error {
 
 	setString(sheet, 1, 1, t.Name).SetFont(excl.Font{Bold: true})
 	setString(sheet, 2, 1, t.Comment)
+	if len(t.Labels) > 0 {
+		setString(sheet, 3, 1, fmt.Sprintf("Labels: %s", joinLabels(t.Labels)))
+	}
 
 	setString(sheet, 4, 1, "Columns").SetFont(excl.Font{Bold: true})
-	setHeader(sheet, 5, []string{"Name", "Type", "Default", "Nullable", "Children", "Parents", "Comment"})
+	setHeader(sheet, 5, ct.Header)
 	r := 6
-	for i, c := range t.Columns {
-		setStringWithBorder(sheet, r+i, 1, c.Name)
-		setStringWithBorder(sheet, r+i, 2, c.Type)
-		setStringWithBorder(sheet, r+i, 3, c.Default.String)
-		setStringWithBorder(sheet, r+i, 4, fmt.Sprintf("%v", c.Nullable))
-		children := []string{}
-		for _, child := range c.ChildRelations {
-			children = append(children, child.Table.Name)
-		}
-		setStringWithBorder(sheet, r+i, 5, strings.Join(children, "\n"))
-		parents := []string{}
-		for _, parent := range c.ParentRelations {
-			parents = append(parents, parent.ParentTable.Name)
+	for i, row := range ct.Rows {
+		for j, v := range row {
+			setStringWithBorder(sheet, r+i, j+1, v)
 		}
-		setStringWithBorder(sheet, r+i, 6, strings.Join(parents, "\n"))
-		setStringWithBorder(sheet, r+i, 7, c.Comment)
 	}
 	r = r + len(t.Columns)
 
@@ -182,6 +224,14 @@ func createTableSheet(w *excl.Workbook, t *schema.Table) error {
 	return nil
 }
 
+func joinLabels(labels schema.Labels) string {
+	ls := []string{}
+	for _, l := range labels {
+		ls = append(ls, l.String())
+	}
+	return strings.Join(ls, ", ")
+}
+
 func setHeader(sheet *excl.Sheet, rowNo int, values []string) {
 	for i, v := range values {
 		sheet.SetColWidth(10, i+1)
@@ -4,12 +4,16 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/pkg/errors"
 )
 
+var fileNameRe = regexp.MustCompile(`[*?:"<>|\\/\x00-\x1f]`)
+
 const (
 	TypeFK = "FOREIGN KEY"
 )
@@ -39,13 +43,24 @@ type Trigger struct {
 	Def  string `json:"def"`
 }
 
+// Label is the struct for table/column label ( e.g. `pii`, `owner: team-growth` )
+type Label struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Labels is the labels of table/column
+type Labels []*Label
+
 // Column is the struct for table column
 type Column struct {
 	Name            string         `json:"name"`
 	Type            string         `json:"type"`
 	Nullable        bool           `json:"nullable"`
+	Repeated        bool           `json:"repeated,omitempty"`
 	Default         sql.NullString `json:"default"`
 	Comment         string         `json:"comment"`
+	Labels          Labels         `json:"labels,omitempty"`
 	ParentRelations []*Relation    `json:"-"`
 	ChildRelations  []*Relation    `json:"-"`
 }
@@ -55,11 +70,21 @@ type Table struct {
 	Name        string        `json:"name"`
 	Type        string        `json:"type"`
 	Comment     string        `json:"comment"`
+	Labels      Labels        `json:"labels,omitempty" yaml:"labels,omitempty"`
 	Columns     []*Column     `json:"columns"`
 	Indexes     []*Index      `json:"indexes"`
 	Constraints []*Constraint `json:"constraints"`
 	Triggers    []*Trigger    `json:"triggers"`
 	Def         string        `json:"def"`
+	External    bool          `json:"external,omitempty" yaml:"external,omitempty"`
+	Shard       *Shard        `json:"shard,omitempty" yaml:"shard,omitempty"`
+}
+
+// Shard is the range of date-sharded tables ( e.g. BigQuery `events_20200101` ) collapsed into one table
+type Shard struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+	Count int    `json:"count"`
 }
 
 // Relation is the struct for table relation
@@ -70,6 +95,7 @@ type Relation struct {
 	ParentColumns []*Column `json:"parent_columns" yaml:"parentColumns"`
 	Def           string    `json:"def"`
 	Virtual  bool      `json:"virtual" yaml:"virtual"`
+	Count         int       `json:"count,omitempty" yaml:"count,omitempty"`
 }
 
 // Driver is the struct for tbls driver information
@@ -126,20 +152,26 @@ func (t Table) MarshalJSON() ([]byte, error) {
 		Name        string        `json:"name"`
 		Type        string        `json:"type"`
 		Comment     string        `json:"comment"`
+		Labels      Labels        `json:"labels,omitempty"`
 		Columns     []*Column     `json:"columns"`
 		Indexes     []*Index      `json:"indexes"`
 		Constraints []*Constraint `json:"constraints"`
 		Triggers    []*Trigger    `json:"triggers"`
 		Def         string        `json:"def"`
+		External    bool          `json:"external,omitempty"`
+		Shard       *Shard        `json:"shard,omitempty"`
 	}{
 		Name:        t.Name,
 		Type:        t.Type,
 		Comment:     t.Comment,
+		Labels:      t.Labels,
 		Columns:     t.Columns,
 		Indexes:     t.Indexes,
 		Constraints: t.Constraints,
 		Triggers:    t.Triggers,
 		Def:         t.Def,
+		External:    t.External,
+		Shard:       t.Shard,
 	})
 }
 
@@ -150,16 +182,20 @@ func (c Column) MarshalJSON() ([]byte, error) {
 			Name            string      `json:"name"`
 			Type            string      `json:"type"`
 			Nullable        bool        `json:"nullable"`
+			Repeated        bool        `json:"repeated,omitempty"`
 			Default         string      `json:"default"`
 			Comment         string      `json:"comment"`
+			Labels          Labels      `json:"labels,omitempty"`
 			ParentRelations []*Relation `json:"-"`
 			ChildRelations  []*Relation `json:"-"`
 		}{
 			Name:            c.Name,
 			Type:            c.Type,
 			Nullable:        c.Nullable,
+			Repeated:        c.Repeated,
 			Default:         c.Default.String,
 			Comment:         c.Comment,
+			Labels:          c.Labels,
 			ParentRelations: c.ParentRelations,
 			ChildRelations:  c.ChildRelations,
 		})
@@ -168,16 +204,20 @@ func (c Column) MarshalJSON() ([]byte, error) {
 		Name            string      `json:"name"`
 		Type            string      `json:"type"`
 		Nullable        bool        `json:"nullable"`
+		Repeated        bool        `json:"repeated,omitempty"`
 		Default         *string     `json:"default"`
 		Comment         string      `json:"comment"`
+		Labels          Labels      `json:"labels,omitempty"`
 		ParentRelations []*Relation `json:"-"`
 		ChildRelations  []*Relation `json:"-"`
 	}{
 		Name:            c.Name,
 		Type:            c.Type,
 		Nullable:        c.Nullable,
+		Repeated:        c.Repeated,
 		Default:         nil,
 		Comment:         c.Comment,
+		Labels:          c.Labels,
 		ParentRelations: c.ParentRelations,
 		ChildRelations:  c.ChildRelations,
 	})
@@ -189,8 +229,10 @@ func (c *Column) UnmarshalJSON(data []byte) error {
 		Name            string      `json:"name"`
 		Type            string      `json:"type"`
 		Nullable        bool        `json:"nullable"`
+		Repeated        bool        `json:"repeated,omitempty"`
 		Default         *string     `json:"default"`
 		Comment         string      `json:"comment"`
+		Labels          Labels      `json:"labels,omitempty"`
 		ParentRelations []*Relation `json:"-"`
 		ChildRelations  []*Relation `json:"-"`
 	}{}
@@ -201,6 +243,7 @@ func (c *Column) UnmarshalJSON(data []byte) error {
 	c.Name = s.Name
 	c.Type = s.Type
 	c.Nullable = s.Nullable
+	c.Repeated = s.Repeated
 	if s.Default != nil {
 		c.Default.Valid = true
 		c.Default.String = *s.Default
@@ -209,6 +252,7 @@ func (c *Column) UnmarshalJSON(data []byte) error {
 		c.Default.String = ""
 	}
 	c.Comment = s.Comment
+	c.Labels = s.Labels
 	return nil
 }
 
@@ -219,16 +263,20 @@ func (c Column) MarshalYAML() ([]byte, error) {
 			Name            string      `yaml:"name"`
 			Type            string      `yaml:"type"`
 			Nullable        bool        `yaml:"nullable"`
+			Repeated        bool        `yaml:"repeated,omitempty"`
 			Default         string      `yaml:"default"`
 			Comment         string      `yaml:"comment"`
+			Labels          Labels      `yaml:"labels,omitempty"`
 			ParentRelations []*Relation `yaml:"-"`
 			ChildRelations  []*Relation `yaml:"-"`
 		}{
 			Name:            c.Name,
 			Type:            c.Type,
 			Nullable:        c.Nullable,
+			Repeated:        c.Repeated,
 			Default:         c.Default.String,
 			Comment:         c.Comment,
+			Labels:          c.Labels,
 			ParentRelations: c.ParentRelations,
 			ChildRelations:  c.ChildRelations,
 		})
@@ -237,16 +285,20 @@ func (c Column) MarshalYAML() ([]byte, error) {
 		Name            string      `yaml:"name"`
 		Type            string      `yaml:"type"`
 		Nullable        bool        `yaml:"nullable"`
+		Repeated        bool        `yaml:"repeated,omitempty"`
 		Default         *string     `yaml:"default"`
 		Comment         string      `yaml:"comment"`
+		Labels          Labels      `yaml:"labels,omitempty"`
 		ParentRelations []*Relation `yaml:"-"`
 		ChildRelations  []*Relation `yaml:"-"`
 	}{
 		Name:            c.Name,
 		Type:            c.Type,
 		Nullable:        c.Nullable,
+		Repeated:        c.Repeated,
 		Default:         nil,
 		Comment:         c.Comment,
+		Labels:          c.Labels,
 		ParentRelations: c.ParentRelations,
 		ChildRelations:  c.ChildRelations,
 	})
@@ -258,8 +310,10 @@ func (c *Column) UnmarshalYAML(data []byte) error {
 		Name            string      `yaml:"name"`
 		Type            string      `yaml:"type"`
 		Nullable        bool        `yaml:"nullable"`
+		Repeated        bool        `yaml:"repeated,omitempty"`
 		Default         *string     `yaml:"default"`
 		Comment         string      `yaml:"comment"`
+		Labels          Labels      `yaml:"labels,omitempty"`
 		ParentRelations []*Relation `yaml:"-"`
 		ChildRelations  []*Relation `yaml:"-"`
 	}{}
@@ -270,6 +324,7 @@ func (c *Column) UnmarshalYAML(data []byte) error {
 	c.Name = s.Name
 	c.Type = s.Type
 	c.Nullable = s.Nullable
+	c.Repeated = s.Repeated
 	if s.Default != nil {
 		c.Default.Valid = true
 		c.Default.String = *s.Default
@@ -278,9 +333,47 @@ func (c *Column) UnmarshalYAML(data []byte) error {
 		c.Default.String = ""
 	}
 	c.Comment = s.Comment
+	c.Labels = s.Labels
 	return nil
 }
 
+// String return `name` or `name: value`
+func (l *Label) String() string {
+	if l.Value == "" {
+		return l.Name
+	}
+	return fmt.Sprintf("%s: %s", l.Name, l.Value)
+}
+
+// Merge add the label, or replace the value of the label that has the same name
+func (ls Labels) Merge(name, value string) Labels {
+	for _, l := range ls {
+		if l.Name == name {
+			l.Value = value
+			return ls
+		}
+	}
+	return append(ls, &Label{Name: name, Value: value})
+}
+
+// Match return true if the labels have the label. label is `name` or `name:value`
+func (ls Labels) Match(label string) bool {
+	name := label
+	value := ""
+	hasValue := false
+	if i := strings.Index(label, ":"); i > 0 {
+		name = strings.TrimSpace(label[:i])
+		value = strings.TrimSpace(label[i+1:])
+		hasValue = true
+	}
+	for _, l := range ls {
+		if l.Name == name && (!hasValue || l.Value == value) {
+			return true
+		}
+	}
+	return false
+}
+
 // FindTableByName find table by table name
 func (s *Schema) FindTableByName(name string) (*Table, error) {
 	for _, t := range s.Tables {
@@ -291,6 +384,12 @@ func (s *Schema) FindTableByName(name string) (*Table, error) {
 	return nil, errors.WithStack(fmt.Errorf("not found table '%s'", name))
 }
 
+// FileName return the base name of the table document and ER diagram.
+// Characters that can not be used in file names ( e.g. `*` of wildcard table ) are replaced with `_`.
+func (t *Table) FileName() string {
+	return fileNameRe.ReplaceAllString(t.Name, "_")
+}
+
 // FindColumnByName find column by column name
 func (t *Table) FindColumnByName(name string) (*Column, error) {
 	for _, c := range t.Columns {
@@ -301,6 +400,46 @@ func (t *Table) FindColumnByName(name string) (*Column, error) {
 	return nil, errors.WithStack(fmt.Errorf("not found column '%s.%s'", t.Name, name))
 }
 
+// IsPrimaryKey return true if the table has primary key exactly on the columns
+func (t *Table) IsPrimaryKey(columns []string) bool {
+	return t.hasKey("PRIMARY", columns)
+}
+
+// IsUnique return true if the table has primary key or unique constraint/index exactly on the columns
+func (t *Table) IsUnique(columns []string) bool {
+	return t.hasKey("PRIMARY", columns) || t.hasKey("UNIQUE", columns)
+}
+
+// InPrimaryKey return true if the column is a part of the primary key of the table
+func (t *Table) InPrimaryKey(column string) bool {
+	for _, c := range t.Constraints {
+		if strings.Contains(c.Type, "PRIMARY") && containsString(c.Columns, column) {
+			return true
+		}
+	}
+	for _, i := range t.Indexes {
+		if strings.Contains(i.Def, "PRIMARY") && containsString(i.Columns, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKey return true if the table has constraint/index of keyType ( `PRIMARY` or `UNIQUE` ) exactly on the columns
+func (t *Table) hasKey(keyType string, columns []string) bool {
+	for _, c := range t.Constraints {
+		if strings.Contains(c.Type, keyType) && sameColumns(c.Columns, columns) {
+			return true
+		}
+	}
+	for _, i := range t.Indexes {
+		if strings.Contains(i.Def, keyType) && sameColumns(i.Columns, columns) {
+			return true
+		}
+	}
+	return false
+}
+
 // Sort schema tables, columns, relations, and constrains
 func (s *Schema) Sort() error {
 	for _, t := range s.Tables {
@@ -366,3 +505,104 @@ func (s *Schema) Repair() error {
 	}
 	return nil
 }
+
+// FilterTablesByLabels keep only tables that have one of the labels ( on the table or its columns ).
+// Relations to removed tables are removed.
+func (s *Schema) FilterTablesByLabels(labels []string) {
+	if len(labels) == 0 {
+		return
+	}
+	keep := map[*Table]bool{}
+	tables := []*Table{}
+	for _, t := range s.Tables {
+		if t.MatchLabels(labels) {
+			keep[t] = true
+			tables = append(tables, t)
+		}
+	}
+	s.Tables = tables
+
+	relations := []*Relation{}
+	for _, r := range s.Relations {
+		if keep[r.Table] && keep[r.ParentTable] {
+			relations = append(relations, r)
+		}
+	}
+	s.Relations = relations
+
+	for _, t := range s.Tables {
+		for _, c := range t.Columns {
+			childRelations := []*Relation{}
+			for _, r := range c.ChildRelations {
+				if keep[r.Table] {
+					childRelations = append(childRelations, r)
+				}
+			}
+			c.ChildRelations = childRelations
+			parentRelations := []*Relation{}
+			for _, r := range c.ParentRelations {
+				if keep[r.ParentTable] {
+					parentRelations = append(parentRelations, r)
+				}
+			}
+			c.ParentRelations = parentRelations
+		}
+	}
+}
+
+// MatchLabels return true if the table or its columns have one of the labels
+func (t *Table) MatchLabels(labels []string) bool {
+	for _, l := range labels {
+		if t.Labels.Match(l) {
+			return true
+		}
+		for _, c := range t.Columns {
+			if c.Labels.Match(l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Subset return the schema that has the tables and the relations between them.
+// Tables are shared with the original schema.
+func (s *Schema) Subset(name string, tables []*Table) *Schema {
+	in := map[*Table]bool{}
+	for _, t := range tables {
+		in[t] = true
+	}
+	relations := []*Relation{}
+	for _, r := range s.Relations {
+		if in[r.Table] && in[r.ParentTable] {
+			relations = append(relations, r)
+		}
+	}
+	return &Schema{
+		Name:      name,
+		Tables:    tables,
+		Relations: relations,
+		Driver:    s.Driver,
+	}
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	for _, c := range a {
+		if !containsString(b, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
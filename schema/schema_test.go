@@ -52,6 +52,58 @@ func TestTable_FindColumnByName(t *testing.T) {
 	}
 }
 
+func TestTable_FileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"users", "users"},
+		{"dataset.events_20200101", "dataset.events_20200101"},
+		{"dataset.events_*", "dataset.events__"},
+		{"a/b:c", "a_b_c"},
+	}
+	for _, tt := range tests {
+		if got := (&Table{Name: tt.name}).FileName(); got != tt.want {
+			t.Errorf("%s: got %v\nwant %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTable_IsUnique(t *testing.T) {
+	table := &Table{
+		Name: "testtable",
+		Constraints: []*Constraint{
+			&Constraint{Name: "PRIMARY", Type: "PRIMARY KEY", Columns: []string{"a", "b"}},
+		},
+		Indexes: []*Index{
+			&Index{Name: "c_unique", Def: "CREATE UNIQUE INDEX c_unique ON testtable (c)", Columns: []string{"c"}},
+			&Index{Name: "d_idx", Def: "CREATE INDEX d_idx ON testtable (d)", Columns: []string{"d"}},
+		},
+	}
+	tests := []struct {
+		columns    []string
+		primaryKey bool
+		unique     bool
+	}{
+		{[]string{"b", "a"}, true, true},
+		{[]string{"a"}, false, false},
+		{[]string{"c"}, false, true},
+		{[]string{"d"}, false, false},
+		{[]string{}, false, false},
+	}
+	for _, tt := range tests {
+		if got := table.IsPrimaryKey(tt.columns); got != tt.primaryKey {
+			t.Errorf("IsPrimaryKey(%v): got %v\nwant %v", tt.columns, got, tt.primaryKey)
+		}
+		if got := table.IsUnique(tt.columns); got != tt.unique {
+			t.Errorf("IsUnique(%v): got %v\nwant %v", tt.columns, got, tt.unique)
+		}
+	}
+	if !table.InPrimaryKey("a") || table.InPrimaryKey("c") {
+		t.Errorf("InPrimaryKey: got %v, %v\nwant true, false", table.InPrimaryKey("a"), table.InPrimaryKey("c"))
+	}
+}
+
 func TestSchema_Sort(t *testing.T) {
 	schema := Schema{
 		Name: "testschema",
@@ -126,6 +178,86 @@ func TestRepair(t *testing.T) {
 	}
 }
 
+func TestLabels_Match(t *testing.T) {
+	labels := Labels{
+		&Label{Name: "pii"},
+		&Label{Name: "owner", Value: "team-growth"},
+	}
+	tests := []struct {
+		label string
+		want  bool
+	}{
+		{"pii", true},
+		{"owner", true},
+		{"owner:team-growth", true},
+		{"owner: team-growth", true},
+		{"owner:team-ads", false},
+		{"deprecated", false},
+	}
+	for _, tt := range tests {
+		got := labels.Match(tt.label)
+		if got != tt.want {
+			t.Errorf("%s: got %v\nwant %v", tt.label, got, tt.want)
+		}
+	}
+	labels = labels.Merge("owner", "team-ads")
+	if len(labels) != 2 || labels[1].String() != "owner: team-ads" {
+		t.Errorf("got %v\nwant [pii owner: team-ads]", labels)
+	}
+}
+
+func TestSchema_FilterTablesByLabels(t *testing.T) {
+	tests := []struct {
+		labels     []string
+		wantTables int
+		wantRels   int
+	}{
+		{[]string{}, 2, 1},
+		{[]string{"pii"}, 1, 0},
+		{[]string{"pii", "owner:team-growth"}, 2, 1},
+		{[]string{"owner:team-ads"}, 0, 0},
+	}
+	for _, tt := range tests {
+		s := newTestSchema()
+		s.Tables[0].Labels = Labels{&Label{Name: "owner", Value: "team-growth"}}
+		s.Tables[1].Columns[1].Labels = Labels{&Label{Name: "pii"}}
+		s.FilterTablesByLabels(tt.labels)
+		if len(s.Tables) != tt.wantTables {
+			t.Errorf("%v: got %v\nwant %v", tt.labels, len(s.Tables), tt.wantTables)
+		}
+		if len(s.Relations) != tt.wantRels {
+			t.Errorf("%v: got %v\nwant %v", tt.labels, len(s.Relations), tt.wantRels)
+		}
+		for _, tbl := range s.Tables {
+			for _, c := range tbl.Columns {
+				if len(c.ParentRelations)+len(c.ChildRelations) > tt.wantRels {
+					t.Errorf("%v: relations of %s.%s are not removed", tt.labels, tbl.Name, c.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestColumnLabelsJSON(t *testing.T) {
+	c := &Column{Name: "email", Type: "text", Labels: Labels{&Label{Name: "pii"}, &Label{Name: "retention", Value: "90d"}}}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"email","type":"text","nullable":false,"default":null,"comment":"","labels":[{"name":"pii"},{"name":"retention","value":"90d"}]}`
+	if string(b) != want {
+		t.Errorf("got %s\nwant %s", b, want)
+	}
+	got := &Column{}
+	err = json.Unmarshal(b, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Labels) != 2 || got.Labels[1].Value != "90d" {
+		t.Errorf("got %v\nwant %v", got.Labels, c.Labels)
+	}
+}
+
 func compareStrings(tb testing.TB, actual, expected string) {
 	tb.Helper()
 	if actual != expected {
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Melsoft-Games/tbls/config"
+	"github.com/Melsoft-Games/tbls/datasource"
+	"github.com/Melsoft-Games/tbls/output"
+	"github.com/Melsoft-Games/tbls/output/dot"
+	"github.com/Melsoft-Games/tbls/output/er"
+	"github.com/Melsoft-Games/tbls/output/html"
+	"github.com/Melsoft-Games/tbls/output/md"
+	"github.com/Melsoft-Games/tbls/schema"
+	"github.com/pkg/errors"
+)
+
+// Server is the local web UI for `tbls serve`
+type Server struct {
+	configPath string
+	options    []config.Option
+	mu         sync.RWMutex
+	config     *config.Config
+	schema     *schema.Schema
+	modTime    time.Time
+}
+
+// NewServer return Server
+func NewServer(configPath string, options ...config.Option) *Server {
+	return &Server{
+		configPath: configPath,
+		options:    options,
+	}
+}
+
+// Analyze load config and analyze database
+func (s *Server) Analyze() error {
+	c, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+	err = c.Load(s.configPath, s.options...)
+	if err != nil {
+		return err
+	}
+	for _, w := range c.Warnings() {
+		log.Printf("Warning: %s", w)
+	}
+	sc, err := datasource.Analyze(c.DSN)
+	if err != nil {
+		return err
+	}
+	err = c.ModifySchema(sc)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = c
+	s.schema = sc
+	s.modTime = s.configModTime()
+	return nil
+}
+
+// Watch re-analyze database when the config file is changed
+func (s *Server) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.mu.RLock()
+		changed := !s.configModTime().Equal(s.modTime)
+		s.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		log.Printf("config file changed. re-analyzing...")
+		err := s.Analyze()
+		if err != nil {
+			log.Printf("failed to re-analyze: %s", err)
+			s.mu.Lock()
+			s.modTime = s.configModTime()
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *Server) configModTime() time.Time {
+	p := s.configPath
+	if p == "" {
+		p = config.DefaultConfigFilePath
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// ServeHTTP serve document pages, ER diagrams and search index
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/-/reanalyze" {
+		s.reanalyze(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	c := s.config
+	sc := s.schema
+	s.mu.RUnlock()
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	buf := new(bytes.Buffer)
+	var (
+		contentType string
+		err         error
+	)
+	switch {
+	case name == "" || name == "index.html":
+		contentType = "text/html; charset=utf-8"
+		err = html.NewServeHTML(c).OutputSchema(buf, sc)
+	case name == "README.md":
+		contentType = "text/markdown; charset=utf-8"
+		err = md.NewMd(c, false, sc.Driver).OutputSchema(buf, sc)
+	case name == "search-index.js":
+		contentType = "application/javascript; charset=utf-8"
+		err = html.OutputSearchIndex(buf, sc)
+	case name == "schema.svg":
+		contentType = "image/svg+xml"
+		err = newSVG(c).OutputSchema(buf, sc)
+	case strings.HasSuffix(name, ".html"):
+		contentType = "text/html; charset=utf-8"
+		err = s.outputTable(buf, sc, strings.TrimSuffix(name, ".html"), html.NewServeHTML(c).OutputTable)
+	case strings.HasSuffix(name, ".md"):
+		contentType = "text/markdown; charset=utf-8"
+		err = s.outputTable(buf, sc, strings.TrimSuffix(name, ".md"), md.NewMd(c, false, sc.Driver).OutputTable)
+	case strings.HasSuffix(name, ".svg"):
+		contentType = "image/svg+xml"
+		err = s.outputTable(buf, sc, strings.TrimSuffix(name, ".svg"), newSVG(c).OutputTable)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err == errNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("%+v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = buf.WriteTo(w)
+}
+
+var errNotFound = errors.New("not found")
+
+func (s *Server) outputTable(wr io.Writer, sc *schema.Schema, name string, output func(io.Writer, *schema.Table) error) error {
+	for _, t := range sc.Tables {
+		if t.FileName() == name {
+			return output(wr, t)
+		}
+	}
+	return errNotFound
+}
+
+func (s *Server) reanalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	err := s.Analyze()
+	if err != nil {
+		log.Printf("failed to re-analyze: %s", err)
+		http.Error(w, fmt.Sprintf("failed to re-analyze: %s", err), http.StatusInternalServerError)
+		return
+	}
+	back := r.Referer()
+	if back == "" {
+		back = "/"
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// sameOrigin return true if the request is sent from the page of the server.
+// Browsers send Origin ( or Referer ) with cross-origin POST, so requests from other sites are rejected.
+func sameOrigin(r *http.Request) bool {
+	from := r.Header.Get("Origin")
+	if from == "" {
+		from = r.Referer()
+	}
+	if from == "" {
+		return true
+	}
+	u, err := url.Parse(from)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
+
+// newSVG return output.Output for SVG ER diagram.
+// Graphviz `dot` command is used if available, otherwise native renderer is used.
+func newSVG(c *config.Config) output.Output {
+	if _, err := exec.LookPath("dot"); err == nil {
+		return &graphviz{dot: dot.NewDot(c)}
+	}
+	return er.NewER(c, "svg")
+}
+
+// graphviz render dot source as SVG
+type graphviz struct {
+	dot *dot.Dot
+}
+
+func (g *graphviz) OutputSchema(wr io.Writer, s *schema.Schema) error {
+	src := new(bytes.Buffer)
+	err := g.dot.OutputSchema(src, s)
+	if err != nil {
+		return err
+	}
+	return dot.Render(wr, src, "svg")
+}
+
+func (g *graphviz) OutputTable(wr io.Writer, t *schema.Table) error {
+	src := new(bytes.Buffer)
+	err := g.dot.OutputTable(src, t)
+	if err != nil {
+		return err
+	}
+	return dot.Render(wr, src, "svg")
+}
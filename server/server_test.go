@@ -0,0 +1,122 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Melsoft-Games/tbls/config"
+)
+
+var tests = []struct {
+	path     string
+	status   int
+	contains string
+}{
+	{"/", http.StatusOK, `<a href="users.html">users</a>`},
+	{"/users.html", http.StatusOK, `<img src="users.svg" alt="er">`},
+	{"/users.md", http.StatusOK, "# users"},
+	{"/search-index.js", http.StatusOK, `"table":"users"`},
+	{"/not_found.html", http.StatusNotFound, ""},
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := NewServer("../testdata/empty.yml", config.DSN([]string{"json://../testdata/testdb.json"}))
+	err := s.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	for _, tt := range tests {
+		res, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if res.StatusCode != tt.status {
+			t.Errorf("%s: actual %v\nwant %v", tt.path, res.StatusCode, tt.status)
+		}
+		if !strings.Contains(string(body), tt.contains) {
+			t.Errorf("%s: actual %v\nwant contains %v", tt.path, string(body), tt.contains)
+		}
+	}
+}
+
+func TestServeWildcardTable(t *testing.T) {
+	s := NewServer("../testdata/empty.yml", config.DSN([]string{"json://../testdata/testdb.json"}))
+	err := s.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := s.schema.FindTableByName("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users.Name = "users_*"
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	for _, tt := range []struct {
+		path     string
+		contains string
+	}{
+		{"/users__.html", `<img src="users__.svg" alt="er">`},
+		{"/users__.svg", "<svg"},
+	} {
+		res, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("%s: actual %v\nwant %v", tt.path, res.StatusCode, http.StatusOK)
+		}
+		if !strings.Contains(string(body), tt.contains) {
+			t.Errorf("%s: actual %v\nwant contains %v", tt.path, string(body), tt.contains)
+		}
+	}
+}
+
+func TestReanalyzeOrigin(t *testing.T) {
+	s := NewServer("../testdata/empty.yml", config.DSN([]string{"json://../testdata/testdb.json"}))
+	err := s.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	origins := []struct {
+		origin string
+		status int
+	}{
+		{"", http.StatusSeeOther},
+		{ts.URL, http.StatusSeeOther},
+		{"http://evil.example.com", http.StatusForbidden},
+	}
+	for _, tt := range origins {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/-/reanalyze", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != tt.status {
+			t.Errorf("%s: actual %v\nwant %v", tt.origin, res.StatusCode, tt.status)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+-- ad-hoc queries
+SELECT * FROM comments JOIN profiles USING (user_id);
+SELECT * FROM posts p JOIN users u ON p.user_id = u.id;